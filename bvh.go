@@ -0,0 +1,322 @@
+package stl
+
+// This file contains a bounding volume hierarchy used to accelerate
+// spatial queries on triangles.
+
+import (
+	"math"
+)
+
+// aabb is an axis-aligned bounding box.
+type aabb struct {
+	min, max Vec3
+}
+
+// emptyAABB returns a box that contains nothing, and grows to
+// the first point added using extend.
+func emptyAABB() aabb {
+	inf := math.Inf(1)
+	return aabb{min: Vec3{inf, inf, inf}, max: Vec3{-inf, -inf, -inf}}
+}
+
+func (b *aabb) extend(p Vec3) {
+	for d := 0; d < 3; d++ {
+		b.min[d] = min(b.min[d], p[d])
+		b.max[d] = max(b.max[d], p[d])
+	}
+}
+
+func (b *aabb) extendBox(o aabb) {
+	b.extend(o.min)
+	b.extend(o.max)
+}
+
+// grow enlarges the box by tol in every direction.
+func (b aabb) grow(tol float64) aabb {
+	d := Vec3{tol, tol, tol}
+	return aabb{min: b.min.Diff(d), max: b.max.Add(d)}
+}
+
+func (b aabb) overlaps(o aabb) bool {
+	return b.min[0] <= o.max[0] && o.min[0] <= b.max[0] &&
+		b.min[1] <= o.max[1] && o.min[1] <= b.max[1] &&
+		b.min[2] <= o.max[2] && o.min[2] <= b.max[2]
+}
+
+// distance returns the distance of p from the box, being 0 inside.
+func (b aabb) distance(p Vec3) float64 {
+	var d Vec3
+	for i := 0; i < 3; i++ {
+		d[i] = math.Max(0, math.Max(b.min[i]-p[i], p[i]-b.max[i]))
+	}
+	return d.Len()
+}
+
+// rayEntry returns the distance along a ray from origin to where it enters
+// the box, or +Inf if it misses the box. invDir contains the reciprocals of
+// the ray direction's coordinates.
+func (b aabb) rayEntry(origin, invDir Vec3) float64 {
+	near, far := 0.0, math.Inf(1)
+	for d := 0; d < 3; d++ {
+		t0 := (b.min[d] - origin[d]) * invDir[d]
+		t1 := (b.max[d] - origin[d]) * invDir[d]
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		// NaN from 0 * Inf means the origin is on the slab boundary of a
+		// parallel ray, which counts as inside
+		if t0 > near {
+			near = t0
+		}
+		if t1 < far {
+			far = t1
+		}
+		if near > far {
+			return math.Inf(1)
+		}
+	}
+	return near
+}
+
+func (b aabb) center() Vec3 {
+	return b.min.Add(b.max).MultScalar(0.5)
+}
+
+func triangleAABB(t *Triangle) aabb {
+	b := emptyAABB()
+	b.extend(t.Vertices[0])
+	b.extend(t.Vertices[1])
+	b.extend(t.Vertices[2])
+	return b
+}
+
+// bvhNode is a node of bvh. Leaves have count > 0 and refer to
+// bvh.order[start:start+count], inner nodes have the children left
+// and left+1.
+type bvhNode struct {
+	box          aabb
+	left         int
+	start, count int
+}
+
+// bvh is a bounding volume hierarchy on triangles.
+type bvh struct {
+	triangles []Triangle
+	boxes     []aabb // bounding box by triangle index
+	nodes     []bvhNode
+	order     []int // triangle indices, sorted by leaf
+}
+
+const bvhLeafSize = 4
+
+// newBVH builds a bounding volume hierarchy for triangles. The triangles
+// must not be changed while the hierarchy is in use.
+func newBVH(triangles []Triangle) *bvh {
+	b := bvh{
+		triangles: triangles,
+		boxes:     make([]aabb, len(triangles)),
+		order:     make([]int, len(triangles)),
+	}
+	centers := make([]Vec3, len(triangles))
+	for i := range triangles {
+		b.boxes[i] = triangleAABB(&triangles[i])
+		centers[i] = b.boxes[i].center()
+		b.order[i] = i
+	}
+	if len(triangles) == 0 {
+		return &b
+	}
+
+	b.nodes = make([]bvhNode, 1, 2*len(triangles)/bvhLeafSize+1)
+	b.nodes[0] = bvhNode{start: 0, count: len(triangles)}
+	stack := []int{0}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &b.nodes[n]
+
+		node.box = emptyAABB()
+		centerBox := emptyAABB()
+		for _, i := range b.order[node.start : node.start+node.count] {
+			node.box.extendBox(b.boxes[i])
+			centerBox.extend(centers[i])
+		}
+		if node.count <= bvhLeafSize {
+			continue
+		}
+
+		// split at the middle of the longest axis of the centers
+		axis := 0
+		extent := centerBox.max.Diff(centerBox.min)
+		if extent[1] > extent[axis] {
+			axis = 1
+		}
+		if extent[2] > extent[axis] {
+			axis = 2
+		}
+		mid := centerBox.center()[axis]
+		items := b.order[node.start : node.start+node.count]
+		split := 0
+		for j := range items {
+			if centers[items[j]][axis] < mid {
+				items[j], items[split] = items[split], items[j]
+				split++
+			}
+		}
+		if split == 0 || split == len(items) {
+			// all centers are equal on this axis
+			split = len(items) / 2
+		}
+
+		left := len(b.nodes)
+		start, count := node.start, node.count
+		node.left = left
+		node.count = 0
+		b.nodes = append(b.nodes,
+			bvhNode{start: start, count: split},
+			bvhNode{start: start + split, count: count - split})
+		stack = append(stack, left, left+1)
+	}
+	return &b
+}
+
+// queryBox calls fn for the index of every triangle whose bounding box
+// overlaps box, until fn returns false.
+func (b *bvh) queryBox(box aabb, fn func(i int) bool) {
+	if len(b.nodes) == 0 {
+		return
+	}
+	var stackBuf [64]int
+	stack := append(stackBuf[:0], 0)
+	for len(stack) > 0 {
+		node := &b.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if !node.box.overlaps(box) {
+			continue
+		}
+		if node.count == 0 {
+			stack = append(stack, node.left, node.left+1)
+			continue
+		}
+		for _, i := range b.order[node.start : node.start+node.count] {
+			if b.boxes[i].overlaps(box) && !fn(i) {
+				return
+			}
+		}
+	}
+}
+
+// closestPoint returns the point on the triangles closest to p, its distance,
+// and the index of its triangle. For an empty hierarchy, the index is -1.
+func (b *bvh) closestPoint(p Vec3) (closest Vec3, dist float64, triangle int) {
+	dist, triangle = math.Inf(1), -1
+	if len(b.nodes) == 0 {
+		return
+	}
+	var stackBuf [64]int
+	stack := append(stackBuf[:0], 0)
+	for len(stack) > 0 {
+		node := &b.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if node.box.distance(p) >= dist {
+			continue
+		}
+		if node.count == 0 {
+			// visit the nearer child first
+			left, right := node.left, node.left+1
+			if b.nodes[left].box.distance(p) < b.nodes[right].box.distance(p) {
+				left, right = right, left
+			}
+			stack = append(stack, left, right)
+			continue
+		}
+		for _, i := range b.order[node.start : node.start+node.count] {
+			if b.boxes[i].distance(p) >= dist {
+				continue
+			}
+			q := closestPointOnTriangle(p, &b.triangles[i])
+			if d := q.Diff(p).Len(); d < dist {
+				closest, dist, triangle = q, d, i
+			}
+		}
+	}
+	return
+}
+
+// closestPointOnTriangle returns the point of t closest to p, following
+// Christer Ericson, "Real-Time Collision Detection".
+func closestPointOnTriangle(p Vec3, t *Triangle) Vec3 {
+	a, b, c := t.Vertices[0], t.Vertices[1], t.Vertices[2]
+	ab, ac, ap := b.Diff(a), c.Diff(a), p.Diff(a)
+	d1, d2 := ab.Dot(ap), ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a
+	}
+	bp := p.Diff(b)
+	d3, d4 := ab.Dot(bp), ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b
+	}
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return a.Add(ab.MultScalar(d1 / (d1 - d3)))
+	}
+	cp := p.Diff(c)
+	d5, d6 := ab.Dot(cp), ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c
+	}
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return a.Add(ac.MultScalar(d2 / (d2 - d6)))
+	}
+	va := d3*d6 - d5*d4
+	if va <= 0 && d4-d3 >= 0 && d5-d6 >= 0 {
+		return b.Add(c.Diff(b).MultScalar((d4 - d3) / ((d4 - d3) + (d5 - d6))))
+	}
+	denom := va + vb + vc
+	if denom == 0 {
+		// degenerate triangle with all vertices equal
+		return a
+	}
+	return a.Add(ab.MultScalar(vb / denom)).Add(ac.MultScalar(vc / denom))
+}
+
+// firstHit returns the distance to the nearest triangle hit by ray, using
+// Ray.IntersectsTriangle, and the index of that triangle. The triangle with
+// index skip is ignored. The direction of the ray must have length 1. If no
+// triangle is hit, the distance is +Inf and the index is -1.
+func (b *bvh) firstHit(ray Ray, skip int) (dist float64, triangle int) {
+	dist, triangle = math.Inf(1), -1
+	if len(b.nodes) == 0 {
+		return
+	}
+	var invDir Vec3
+	for d := 0; d < 3; d++ {
+		invDir[d] = 1 / ray.Direction[d]
+	}
+	var stackBuf [64]int
+	stack := append(stackBuf[:0], 0)
+	for len(stack) > 0 {
+		node := &b.nodes[stack[len(stack)-1]]
+		stack = stack[:len(stack)-1]
+		if node.box.rayEntry(ray.Origin, invDir) >= dist {
+			continue
+		}
+		if node.count == 0 {
+			stack = append(stack, node.left, node.left+1)
+			continue
+		}
+		for _, i := range b.order[node.start : node.start+node.count] {
+			if i == skip || b.boxes[i].rayEntry(ray.Origin, invDir) >= dist {
+				continue
+			}
+			if p, hit := ray.IntersectsTriangle(b.triangles[i]); hit {
+				if d := p.Diff(ray.Origin).Len(); d < dist {
+					dist, triangle = d, i
+				}
+			}
+		}
+	}
+	return
+}
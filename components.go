@@ -0,0 +1,166 @@
+package stl
+
+// This file contains functions to split a solid into its connected
+// components, called shells.
+
+// disjointSet is a union-find data structure on the integers 0..n-1.
+type disjointSet []int
+
+func newDisjointSet(n int) disjointSet {
+	d := make(disjointSet, n)
+	for i := range d {
+		d[i] = i
+	}
+	return d
+}
+
+// find returns the representative of the set containing i.
+func (d disjointSet) find(i int) int {
+	for d[i] != i {
+		d[i] = d[d[i]] // path halving
+		i = d[i]
+	}
+	return i
+}
+
+// union merges the sets containing i and j.
+func (d disjointSet) union(i, j int) {
+	ri, rj := d.find(i), d.find(j)
+	if ri < rj {
+		d[rj] = ri
+	} else if rj < ri {
+		d[ri] = rj
+	}
+}
+
+// undirectedEdge returns a key for the edge between v and w that is
+// independent of the direction.
+func undirectedEdge(v, w uint32) [2]uint32 {
+	if v > w {
+		return [2]uint32{w, v}
+	}
+	return [2]uint32{v, w}
+}
+
+// componentLabels returns the component of every face, with faces sharing
+// an edge being in the same component. Components are numbered in the order
+// of their first face. Also returns the number of components.
+func (m *IndexedMesh) componentLabels() (labels []int, count int) {
+	d := newDisjointSet(len(m.Faces))
+	edgeToFace := make(map[[2]uint32]int, 3*len(m.Faces)/2)
+	for i, f := range m.Faces {
+		for e := 0; e < 3; e++ {
+			key := undirectedEdge(f[e], f[(e+1)%3])
+			if other, found := edgeToFace[key]; found {
+				d.union(i, other)
+			} else {
+				edgeToFace[key] = i
+			}
+		}
+	}
+
+	labels = make([]int, len(m.Faces))
+	labelByRoot := make(map[int]int)
+	for i := range m.Faces {
+		root := d.find(i)
+		label, found := labelByRoot[root]
+		if !found {
+			label = count
+			labelByRoot[root] = label
+			count++
+		}
+		labels[i] = label
+	}
+	return
+}
+
+// isClosed is true if every edge of the mesh has exactly one counter-edge
+// in another face, and no other face uses the same edge. Faces with equal
+// vertices, which can close their own edges, make the mesh not closed.
+func (m *IndexedMesh) isClosed() bool {
+	edgeCount := make(map[[2]uint32]int, 3*len(m.Faces))
+	for i, f := range m.Faces {
+		if m.hasEqualVertices(i) {
+			return false
+		}
+		for e := 0; e < 3; e++ {
+			edgeCount[[2]uint32{f[e], f[(e+1)%3]}]++
+		}
+	}
+	for edge, count := range edgeCount {
+		if count != 1 || edgeCount[[2]uint32{edge[1], edge[0]}] != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Components splits the solid into its connected components, with triangles
+// sharing an edge belonging to the same component. Vertices closer to each
+// other than weldTolerance are considered equal, see ToIndexed. The components
+// are returned in the order of their first triangle in s.Triangles, and share
+// Name, BinaryHeader, and IsAscii with s.
+func (s *Solid) Components(weldTolerance float64) []*Solid {
+	labels, count := s.ToIndexed(weldTolerance).componentLabels()
+	components := make([]*Solid, count)
+	for i := range components {
+		components[i] = &Solid{
+			BinaryHeader: s.BinaryHeader,
+			Name:         s.Name,
+			IsAscii:      s.IsAscii,
+		}
+	}
+	for i, label := range labels {
+		components[label].AppendTriangle(s.Triangles[i])
+	}
+	return components
+}
+
+// Shell describes a connected component of a solid, see Solid.Shells.
+type Shell struct {
+	// Solid contains the triangles of the shell.
+	Solid *Solid
+
+	// TriangleCount is the number of triangles in the shell.
+	TriangleCount int
+
+	// Bounds is the axis-aligned bounding box of the shell.
+	Bounds SolidMeasure
+
+	// Volume is the enclosed volume, which is only meaningful if IsClosed is true.
+	// It is negative if the triangles are oriented inwards.
+	Volume float64
+
+	// IsClosed is true if every edge of the shell is shared with exactly
+	// one other triangle in the opposite direction, i.e. it is watertight.
+	// Triangles with equal vertices make a shell not closed.
+	IsClosed bool
+}
+
+// Shells splits the solid into its connected components like Components,
+// and reports the size of each of them.
+func (s *Solid) Shells(weldTolerance float64) []Shell {
+	components := s.Components(weldTolerance)
+	shells := make([]Shell, len(components))
+	for i, c := range components {
+		shells[i] = Shell{
+			Solid:         c,
+			TriangleCount: len(c.Triangles),
+			Bounds:        c.Measure(),
+			Volume:        c.signedVolume(),
+			IsClosed:      c.ToIndexed(weldTolerance).isClosed(),
+		}
+	}
+	return shells
+}
+
+// signedVolume returns the volume enclosed by the solid, calculated using the
+// divergence theorem. It is only meaningful for closed solids, and negative
+// if the triangles are oriented inwards.
+func (s *Solid) signedVolume() float64 {
+	var volume float64
+	for i := range s.Triangles {
+		volume += s.Triangles[i].signedVolume()
+	}
+	return volume
+}
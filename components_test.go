@@ -0,0 +1,84 @@
+package stl
+
+// Tests for splitting solids into components.
+
+import (
+	"testing"
+)
+
+// makeTwoShellTestSolid returns two copies of the test solid, the second one
+// moved away from the first one.
+func makeTwoShellTestSolid() *Solid {
+	s := makeTestSolid()
+	moved := makeTestSolid()
+	moved.Translate(Vec3{3, 0, 0})
+	s.Triangles = append(s.Triangles, moved.Triangles...)
+	return s
+}
+
+func TestComponents(t *testing.T) {
+	s := makeTwoShellTestSolid()
+	components := s.Components(0)
+	if len(components) != 2 {
+		t.Fatalf("Expected 2 components, found %d", len(components))
+	}
+	if !components[0].sameOrderAlmostEqual(makeTestSolid()) {
+		t.Error("First component not as expected")
+		t.Log("Found:\n", components[0])
+	}
+	if len(components[1].Triangles) != 4 {
+		t.Errorf("Expected 4 triangles in second component, found %d", len(components[1].Triangles))
+	}
+}
+
+func TestComponents_TouchingVertex(t *testing.T) {
+	// Two tetrahedra touching in a single vertex are not edge-connected
+	s := makeTestSolid()
+	moved := makeTestSolid()
+	moved.Translate(Vec3{1, 0, 0})
+	s.Triangles = append(s.Triangles, moved.Triangles...)
+	if n := len(s.Components(0)); n != 2 {
+		t.Errorf("Expected 2 components, found %d", n)
+	}
+}
+
+func TestShells(t *testing.T) {
+	s := makeTwoShellTestSolid()
+	s.Triangles = s.Triangles[:7] // open the second shell
+	shells := s.Shells(0)
+	if len(shells) != 2 {
+		t.Fatalf("Expected 2 shells, found %d", len(shells))
+	}
+	if !shells[0].IsClosed || shells[1].IsClosed {
+		t.Errorf("Expected first shell to be closed and second one open, found %v and %v",
+			shells[0].IsClosed, shells[1].IsClosed)
+	}
+	if !almostEqual64(shells[0].Volume, 1.0/6, 0.000001) {
+		t.Errorf("Expected volume 1/6, found %g", shells[0].Volume)
+	}
+	if shells[1].TriangleCount != 3 {
+		t.Errorf("Expected 3 triangles in second shell, found %d", shells[1].TriangleCount)
+	}
+	if shells[1].Bounds.Min != (Vec3{3, 0, 0}) {
+		t.Errorf("Expected second shell to start at [3 0 0], found %v", shells[1].Bounds.Min)
+	}
+}
+
+func TestShells_Degenerate(t *testing.T) {
+	// a sliver uses its only edge in both directions
+	var s Solid
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {0, 0, 0}, {1, 0, 0}}})
+	if shells := s.Shells(0); len(shells) != 1 || shells[0].IsClosed {
+		t.Errorf("Expected one open shell, found %+v", shells)
+	}
+	if props := s.MassProperties(1); props.IsClosed {
+		t.Error("Expected mass properties of a sliver not to be closed")
+	}
+
+	// a sliver on an edge of a closed solid
+	s = *makeTestSolid()
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {0, 0, 0}, {1, 0, 0}}})
+	if shells := s.Shells(0); len(shells) != 1 || shells[0].IsClosed {
+		t.Errorf("Expected one open shell, found %+v", shells)
+	}
+}
@@ -0,0 +1,164 @@
+package stl
+
+// This file contains the estimation of discrete curvature at the vertices
+// of a mesh.
+
+import (
+	"math"
+)
+
+// VertexCurvature contains the curvature at the vertices of a mesh, see
+// Solid.Curvature.
+type VertexCurvature struct {
+	// Mesh is the welded mesh. The other fields are indexed like
+	// Mesh.Vertices.
+	Mesh *IndexedMesh
+
+	// Gaussian is the Gaussian curvature, the product of the principal
+	// curvatures.
+	Gaussian []float64
+
+	// Mean is the mean curvature, the average of the principal curvatures.
+	// It is positive where the surface is convex.
+	Mean []float64
+
+	// Area is the part of the surface around each vertex the curvature is
+	// averaged over.
+	Area []float64
+}
+
+// Curvature estimates the Gaussian and mean curvature at every vertex. The
+// solid is converted into an IndexedMesh first, merging vertices closer than
+// weldTol, see ToIndexed.
+//
+// The Gaussian curvature is found from the angle deficit, the difference
+// between the angles of the triangles around a vertex and 2π, or π at the
+// border of open solids. The mean curvature is half the length of the
+// cotangent Laplacian of the vertex position. Both are divided by the mixed
+// Voronoi area around the vertex, following Meyer, Desbrun, Schröder and
+// Barr, "Discrete Differential-Geometry Operators for Triangulated
+// 2-Manifolds". Degenerate triangles are left out, and vertices without
+// area get zero curvature.
+func (s *Solid) Curvature(weldTol float64) *VertexCurvature {
+	m := s.ToIndexed(weldTol)
+	n := len(m.Vertices)
+	c := VertexCurvature{
+		Mesh:     m,
+		Gaussian: make([]float64, n),
+		Mean:     make([]float64, n),
+		Area:     make([]float64, n),
+	}
+
+	angleSum := make([]float64, n)
+	laplacian := make([]Vec3, n)
+	normals := make([]Vec3, n)
+	edgeCount := make(map[[2]uint32]int, 3*len(m.Faces)/2)
+	for _, f := range m.Faces {
+		p := [3]Vec3{m.Vertices[f[0]], m.Vertices[f[1]], m.Vertices[f[2]]}
+		cross := p[1].Diff(p[0]).Cross(p[2].Diff(p[0]))
+		area := cross.Len() / 2
+		if area == 0 {
+			continue
+		}
+		for e := 0; e < 3; e++ {
+			edgeCount[undirectedEdge(f[e], f[(e+1)%3])]++
+		}
+
+		var cot [3]float64
+		obtuse := -1
+		for k := 0; k < 3; k++ {
+			a, b := p[(k+1)%3].Diff(p[k]), p[(k+2)%3].Diff(p[k])
+			cot[k] = a.Dot(b) / (2 * area)
+			if cot[k] < 0 {
+				obtuse = k
+			}
+			angleSum[f[k]] += a.Angle(b)
+			normals[f[k]] = normals[f[k]].Add(cross)
+		}
+		for k := 0; k < 3; k++ {
+			// the edge opposite to corner k
+			i, j := (k+1)%3, (k+2)%3
+			d := p[i].Diff(p[j]).MultScalar(cot[k])
+			laplacian[f[i]] = laplacian[f[i]].Add(d)
+			laplacian[f[j]] = laplacian[f[j]].Diff(d)
+		}
+		for k := 0; k < 3; k++ {
+			var voronoi float64
+			switch {
+			case obtuse < 0:
+				i, j := (k+1)%3, (k+2)%3
+				voronoi = (p[j].Diff(p[k]).Dot(p[j].Diff(p[k]))*cot[i] + p[i].Diff(p[k]).Dot(p[i].Diff(p[k]))*cot[j]) / 8
+			case obtuse == k:
+				voronoi = area / 2
+			default:
+				voronoi = area / 4
+			}
+			c.Area[f[k]] += voronoi
+		}
+	}
+
+	fullAngle := make([]float64, n)
+	for i := range fullAngle {
+		fullAngle[i] = 2 * math.Pi
+	}
+	for edge, count := range edgeCount {
+		if count == 1 {
+			fullAngle[edge[0]] = math.Pi
+			fullAngle[edge[1]] = math.Pi
+		}
+	}
+
+	for v := range m.Vertices {
+		if c.Area[v] == 0 {
+			continue
+		}
+		c.Gaussian[v] = (fullAngle[v] - angleSum[v]) / c.Area[v]
+		meanNormal := laplacian[v].MultScalar(1 / (2 * c.Area[v]))
+		c.Mean[v] = meanNormal.Len() / 2
+		if meanNormal.Dot(normals[v]) < 0 {
+			c.Mean[v] = -c.Mean[v]
+		}
+	}
+	return &c
+}
+
+// ColorMap returns the mesh as a solid with every triangle colored by the
+// average of values at its vertices, like Gaussian or Mean. The colors range
+// from blue for lo to red for hi. If lo is not smaller than hi, the range of
+// values is used. The colors are stored in Triangle.Attributes using 5 bits
+// for blue, green and red starting from the lowest bit, and bit 15 set, as
+// understood by VisCAM and SolidView. As the ASCII format cannot store
+// attributes, the solid is written in binary format.
+func (c *VertexCurvature) ColorMap(values []float64, lo, hi float64) *Solid {
+	if lo >= hi {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for _, v := range values {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+	s := c.Mesh.ToSolid()
+	s.IsAscii = false
+	for i, f := range c.Mesh.Faces {
+		value := (values[f[0]] + values[f[1]] + values[f[2]]) / 3
+		x := 0.5
+		if hi > lo {
+			x = math.Max(0, math.Min(1, (value-lo)/(hi-lo)))
+		}
+		s.Triangles[i].Attributes = heatColor(x)
+	}
+	return s
+}
+
+// heatColor returns the color for x from 0 to 1 on a scale from blue over
+// cyan, green and yellow to red, in the 15 bit format of ColorMap.
+func heatColor(x float64) uint16 {
+	// piecewise linear in four segments
+	r := math.Max(0, math.Min(1, 4*x-2))
+	g := math.Max(0, math.Min(1, math.Min(4*x, 4-4*x)))
+	b := math.Max(0, math.Min(1, 2-4*x))
+	channel := func(v float64) uint16 {
+		return uint16(math.Round(v * 31))
+	}
+	return 1<<15 | channel(r)<<10 | channel(g)<<5 | channel(b)
+}
@@ -0,0 +1,87 @@
+package stl
+
+// Tests for the estimation of discrete curvature.
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCurvature_Torus(t *testing.T) {
+	const r1, r2 = 3.0, 1.0
+	c := makeTorusTestSolid(r1, r2, 96, 48).Curvature(0)
+
+	// Gauss-Bonnet: the total curvature of a torus is 0
+	var total float64
+	for v := range c.Gaussian {
+		total += c.Gaussian[v] * c.Area[v]
+	}
+	if math.Abs(total) > 1e-9 {
+		t.Errorf("Expected total Gaussian curvature 0, found %g", total)
+	}
+
+	for _, test := range []struct {
+		point          Vec3
+		gaussian, mean float64
+	}{
+		{point: Vec3{r1 + r2, 0, 0}, gaussian: 1 / (r2 * (r1 + r2)), mean: (r1 + 2*r2) / (2 * r2 * (r1 + r2))},
+		{point: Vec3{r1 - r2, 0, 0}, gaussian: -1 / (r2 * (r1 - r2)), mean: (r1 - 2*r2) / (2 * r2 * (r1 - r2))},
+	} {
+		v := -1
+		for i, p := range c.Mesh.Vertices {
+			if p.AlmostEqual(test.point, 1e-9) {
+				v = i
+			}
+		}
+		if v < 0 {
+			t.Fatalf("Vertex %v not found", test.point)
+		}
+		if !almostEqual64(c.Gaussian[v], test.gaussian, 0.01) || !almostEqual64(c.Mean[v], test.mean, 0.01) {
+			t.Errorf("Expected Gaussian curvature %g and mean curvature %g at %v, found %g and %g",
+				test.gaussian, test.mean, test.point, c.Gaussian[v], c.Mean[v])
+		}
+	}
+}
+
+func TestCurvature_Box(t *testing.T) {
+	c := makeBoxTestSolid(Vec3{1, 2, 3}).Curvature(0)
+	if len(c.Mesh.Vertices) != 8 {
+		t.Fatalf("Expected 8 vertices, found %d", len(c.Mesh.Vertices))
+	}
+	// every corner has an angle deficit of pi/2, and the areas add up to
+	// the surface area
+	var area float64
+	for v := range c.Gaussian {
+		if !almostEqual64(c.Gaussian[v]*c.Area[v], math.Pi/2, 1e-12) {
+			t.Errorf("Expected angle deficit pi/2 at vertex %d, found %g", v, c.Gaussian[v]*c.Area[v])
+		}
+		if c.Mean[v] <= 0 {
+			t.Errorf("Expected positive mean curvature at convex vertex %d, found %g", v, c.Mean[v])
+		}
+		area += c.Area[v]
+	}
+	if !almostEqual64(area, 22, 1e-12) {
+		t.Errorf("Expected total area 22, found %g", area)
+	}
+}
+
+func TestCurvature_ColorMap(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{1, 1, 1})
+	s.IsAscii = true
+	c := s.Curvature(0)
+	values := make([]float64, len(c.Mesh.Vertices))
+	for v, p := range c.Mesh.Vertices {
+		values[v] = p[2]
+	}
+	colored := c.ColorMap(values, 0, 0)
+	if colored.IsAscii || len(colored.Triangles) != 12 {
+		t.Fatalf("Expected 12 triangles in binary format, found %d, ascii %v", len(colored.Triangles), colored.IsAscii)
+	}
+	// bottom, top and a side with the average 1/3
+	expected := map[int]uint16{0: 0x801f, 2: 0xfc00, 4: 0x83f5}
+	for i, color := range expected {
+		if colored.Triangles[i].Attributes != color {
+			t.Errorf("Expected color %#x for triangle %d, found %#x", color, i, colored.Triangles[i].Attributes)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package stl
+
+// This file contains functions to remove degenerate and duplicate triangles.
+
+// RemoveDegenerates removes all triangles with an area not greater than
+// areaTol, including those with equal vertices. With areaTol being 0, only
+// triangles with exactly collinear vertices are removed. Returns the number
+// of triangles removed.
+func (s *Solid) RemoveDegenerates(areaTol float64) int {
+	return s.removeTriangles(func(i int) bool {
+		t := &s.Triangles[i]
+		return t.hasEqualVertices() || t.Area() <= areaTol
+	})
+}
+
+// RemoveDegeneratesWithTolerance works like RemoveDegenerates, but removes
+// the triangles whose height over the longest edge is not greater than tol,
+// which are the ones ValidateWithTolerance reports as IsDegenerate. Unlike
+// with an area, long slivers are removed, and small triangles of good shape
+// are kept. Returns the number of triangles removed.
+func (s *Solid) RemoveDegeneratesWithTolerance(tol float64) int {
+	return s.removeTriangles(func(i int) bool {
+		t := &s.Triangles[i]
+		return t.hasEqualVertices() || t.isCollinear(tol)
+	})
+}
+
+// RemoveDuplicates removes triangles having exactly the same vertices as
+// a triangle before them in s.Triangles, regardless of the orientation and
+// of the order of the vertices. Returns the number of triangles removed.
+func (s *Solid) RemoveDuplicates() int {
+	remove := make([]bool, len(s.Triangles))
+	for _, group := range s.ToIndexed(0).duplicateFaces() {
+		for _, i := range group[1:] {
+			remove[i] = true
+		}
+	}
+	return s.removeTriangles(func(i int) bool {
+		return remove[i]
+	})
+}
+
+// RemoveDegenerates removes all faces with an area not greater than areaTol,
+// like Solid.RemoveDegenerates. The vertices are kept, even if no face uses
+// them anymore. Returns the number of faces removed.
+func (m *IndexedMesh) RemoveDegenerates(areaTol float64) int {
+	return m.removeFaces(func(i int) bool {
+		t := m.Triangle(i)
+		return m.hasEqualVertices(i) || t.Area() <= areaTol
+	})
+}
+
+// RemoveDegeneratesWithTolerance removes all faces whose height over the
+// longest edge is not greater than tol, like
+// Solid.RemoveDegeneratesWithTolerance. The vertices are kept. Returns the
+// number of faces removed.
+func (m *IndexedMesh) RemoveDegeneratesWithTolerance(tol float64) int {
+	return m.removeFaces(func(i int) bool {
+		t := m.Triangle(i)
+		return m.hasEqualVertices(i) || t.isCollinear(tol)
+	})
+}
+
+// RemoveDuplicates removes faces using the same vertices as a face before
+// them in m.Faces, like Solid.RemoveDuplicates. The vertices are kept.
+// Returns the number of faces removed.
+func (m *IndexedMesh) RemoveDuplicates() int {
+	remove := make([]bool, len(m.Faces))
+	for _, group := range m.duplicateFaces() {
+		for _, i := range group[1:] {
+			remove[i] = true
+		}
+	}
+	return m.removeFaces(func(i int) bool {
+		return remove[i]
+	})
+}
+
+// removeTriangles removes the triangles for which remove returns true,
+// keeping the order of the remaining ones. remove is called exactly once
+// for every index of s.Triangles, in ascending order, before the triangle
+// is moved. Returns the number of triangles removed.
+func (s *Solid) removeTriangles(remove func(i int) bool) int {
+	kept := 0
+	for i := range s.Triangles {
+		if !remove(i) {
+			s.Triangles[kept] = s.Triangles[i]
+			kept++
+		}
+	}
+	removed := len(s.Triangles) - kept
+	s.Triangles = s.Triangles[:kept]
+	return removed
+}
+
+// removeFaces removes the faces for which remove returns true, together with
+// their normals and attributes, like Solid.removeTriangles. Returns the
+// number of faces removed.
+func (m *IndexedMesh) removeFaces(remove func(i int) bool) int {
+	hasNormals := len(m.Normals) == len(m.Faces)
+	hasAttributes := len(m.Attributes) == len(m.Faces)
+	kept := 0
+	for i := range m.Faces {
+		if remove(i) {
+			continue
+		}
+		m.Faces[kept] = m.Faces[i]
+		if hasNormals {
+			m.Normals[kept] = m.Normals[i]
+		}
+		if hasAttributes {
+			m.Attributes[kept] = m.Attributes[i]
+		}
+		kept++
+	}
+	removed := len(m.Faces) - kept
+	m.Faces = m.Faces[:kept]
+	if hasNormals {
+		m.Normals = m.Normals[:kept]
+	}
+	if hasAttributes {
+		m.Attributes = m.Attributes[:kept]
+	}
+	return removed
+}
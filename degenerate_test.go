@@ -0,0 +1,115 @@
+package stl
+
+// Tests for removing degenerate and duplicate triangles.
+
+import (
+	"testing"
+)
+
+func TestRemoveDegenerates(t *testing.T) {
+	s := makeTestSolid()
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {1, 1, 1}, {2, 2, 2}}})         // collinear
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {1, 0, 0}, {0.5, 0.00001, 0}}}) // sliver
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {0, 0, 0}, {1, 0, 0}}})         // equal vertices
+	errors := s.Validate()
+	if errors[4] == nil || !errors[4].IsDegenerate {
+		t.Error("Failed to detect degenerate triangle 4")
+	}
+	if errors[5] != nil && errors[5].IsDegenerate {
+		t.Error("Triangle 5 is not exactly degenerate")
+	}
+	if errors := s.ValidateWithTolerance(0.0001); errors[5] == nil || !errors[5].IsDegenerate {
+		t.Error("Failed to detect degenerate triangle 5 with tolerance")
+	}
+
+	if removed := s.RemoveDegenerates(0); removed != 2 {
+		t.Errorf("Expected 2 triangles to be removed, found %d", removed)
+	}
+	if removed := s.RemoveDegenerates(0.0001); removed != 1 {
+		t.Errorf("Expected 1 triangle to be removed, found %d", removed)
+	}
+	if !s.sameOrderAlmostEqual(makeTestSolid()) {
+		t.Error("Expected the original triangles to remain")
+	}
+}
+
+func TestRemoveDegeneratesWithTolerance(t *testing.T) {
+	var s Solid
+	// a long sliver with an area above the tolerance, and a small
+	// triangle of good shape with an area below it
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {1000, 0, 0}, {500, 0.00005, 0}}})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{10, 0, 0}, {10.001, 0, 0}, {10, 0.001, 0}}})
+	const tol = 0.0001
+	errors := s.ValidateWithTolerance(tol)
+	if errors[0] == nil || !errors[0].IsDegenerate {
+		t.Error("Failed to detect degenerate triangle 0")
+	}
+	if errors[1] != nil && errors[1].IsDegenerate {
+		t.Error("Triangle 1 is not degenerate")
+	}
+
+	byArea := Solid{Triangles: append([]Triangle{}, s.Triangles...)}
+	if removed := byArea.RemoveDegenerates(tol); removed != 1 || len(byArea.Triangles) != 1 || byArea.Triangles[0].Vertices[0][0] != 0 {
+		t.Errorf("Expected only triangle 1 to be removed by area, found %d removed", removed)
+	}
+	if removed := s.RemoveDegeneratesWithTolerance(tol); removed != 1 || len(s.Triangles) != 1 || s.Triangles[0].Vertices[0][0] != 10 {
+		t.Errorf("Expected only triangle 0 to be removed by height, found %d removed", removed)
+	}
+}
+
+func TestIndexedMesh_RemoveDegenerates(t *testing.T) {
+	s := makeTestSolid()
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {1, 0, 0}, {0.5, 0.00001, 0}}}) // sliver
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {0, 0, 0}, {1, 0, 0}}})         // equal vertices
+	s.Triangles[5].Attributes = 1
+	m := s.ToIndexed(0)
+	if removed := m.RemoveDegenerates(0); removed != 1 || len(m.Normals) != 5 || m.Attributes[4] != 0 {
+		t.Errorf("Expected triangle 5 with its normal and attributes to be removed, found %d removed", removed)
+	}
+	if removed := m.RemoveDegeneratesWithTolerance(0.0001); removed != 1 || len(m.Attributes) != 4 {
+		t.Errorf("Expected triangle 4 to be removed, found %d removed", removed)
+	}
+	if !m.ToSolid().sameOrderAlmostEqual(makeTestSolid()) {
+		t.Error("Expected the original triangles to remain")
+	}
+}
+
+func TestRemoveDuplicates(t *testing.T) {
+	s := makeTestSolid()
+	duplicate := s.Triangles[1]
+	duplicate.Vertices = [3]Vec3{duplicate.Vertices[1], duplicate.Vertices[2], duplicate.Vertices[0]}
+	flipped := s.Triangles[2]
+	flipped.flip()
+	s.AppendTriangle(duplicate)
+	s.AppendTriangle(flipped)
+
+	errors := s.Validate()
+	if errors[4] == nil || len(errors[4].DuplicateTriangles) != 1 || errors[4].DuplicateTriangles[0] != 1 {
+		t.Error("Failed to detect duplicate of triangle 1 in triangle 4")
+	}
+	if errors[2] == nil || len(errors[2].FlippedDuplicateTriangles) != 1 || errors[2].FlippedDuplicateTriangles[0] != 5 {
+		t.Error("Failed to detect flipped duplicate of triangle 2 in triangle 5")
+	}
+
+	if removed := s.RemoveDuplicates(); removed != 2 {
+		t.Errorf("Expected 2 triangles to be removed, found %d", removed)
+	}
+	if !s.sameOrderAlmostEqual(makeTestSolid()) {
+		t.Error("Expected the original triangles to remain")
+	}
+}
+
+func TestIndexedMesh_RemoveDuplicates(t *testing.T) {
+	s := makeTestSolid()
+	flipped := s.Triangles[2]
+	flipped.flip()
+	s.AppendTriangle(s.Triangles[1])
+	s.AppendTriangle(flipped)
+	m := s.ToIndexed(0)
+	if removed := m.RemoveDuplicates(); removed != 2 || len(m.Normals) != 4 || len(m.Attributes) != 4 {
+		t.Errorf("Expected 2 faces to be removed, found %d", removed)
+	}
+	if !m.ToSolid().sameOrderAlmostEqual(makeTestSolid()) {
+		t.Error("Expected the original triangles to remain")
+	}
+}
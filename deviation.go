@@ -0,0 +1,127 @@
+package stl
+
+// This file contains the comparison of the surfaces of two solids.
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DeviationHistogramBins is the number of bins of DeviationReport.Histogram.
+const DeviationHistogramBins = 20
+
+// DeviationReport is the result of CompareSurfaces. All distances are
+// estimated from points sampled on the surfaces.
+type DeviationReport struct {
+	// HausdorffAB is the largest distance of a point on a from the surface
+	// of b, HausdorffBA vice versa.
+	HausdorffAB, HausdorffBA float64
+
+	// Hausdorff is the symmetric Hausdorff distance, the larger one of
+	// HausdorffAB and HausdorffBA.
+	Hausdorff float64
+
+	// Mean and RMS are the mean and root mean square distance of the sample
+	// points of both surfaces from the other surface.
+	Mean, RMS float64
+
+	// Histogram counts the sample points of both surfaces by their distance
+	// from the other surface, in DeviationHistogramBins bins of equal width
+	// HistogramBinWidth, starting at 0.
+	Histogram         []int
+	HistogramBinWidth float64
+}
+
+// CompareSurfaces measures how much the surfaces of a and b deviate from
+// each other. samples points are distributed uniformly over each surface,
+// and their distance from the other surface is found using a bounding volume
+// hierarchy. The sample points are pseudo-random, so the result is
+// reproducible. If one of the solids has no triangles, the report is empty.
+func CompareSurfaces(a, b *Solid, samples int) DeviationReport {
+	var report DeviationReport
+	if len(a.Triangles) == 0 || len(b.Triangles) == 0 || samples <= 0 {
+		return report
+	}
+	r := rand.New(rand.NewSource(1))
+	ab := sampleDistances(a.sampleSurface(samples, r), newBVH(b.Triangles))
+	ba := sampleDistances(b.sampleSurface(samples, r), newBVH(a.Triangles))
+
+	var sum, sumSquares float64
+	for _, distances := range [][]float64{ab, ba} {
+		for _, d := range distances {
+			sum += d
+			sumSquares += d * d
+		}
+	}
+	for _, d := range ab {
+		report.HausdorffAB = math.Max(report.HausdorffAB, d)
+	}
+	for _, d := range ba {
+		report.HausdorffBA = math.Max(report.HausdorffBA, d)
+	}
+	report.Hausdorff = math.Max(report.HausdorffAB, report.HausdorffBA)
+	count := float64(len(ab) + len(ba))
+	report.Mean = sum / count
+	report.RMS = math.Sqrt(sumSquares / count)
+
+	report.Histogram = make([]int, DeviationHistogramBins)
+	report.HistogramBinWidth = report.Hausdorff / DeviationHistogramBins
+	for _, distances := range [][]float64{ab, ba} {
+		for _, d := range distances {
+			bin := DeviationHistogramBins - 1
+			if report.HistogramBinWidth > 0 && d < report.Hausdorff {
+				bin = int(d / report.HistogramBinWidth)
+			}
+			report.Histogram[bin]++
+		}
+	}
+	return report
+}
+
+// sampleDistances returns the distances of points from the triangles in tree.
+func sampleDistances(points []Vec3, tree *bvh) []float64 {
+	distances := make([]float64, len(points))
+	for i, p := range points {
+		_, distances[i], _ = tree.closestPoint(p)
+	}
+	return distances
+}
+
+// sampleSurface returns n points distributed uniformly over the surface,
+// using r as the source of randomness. If the surface has no area, the
+// points are distributed over the triangles instead.
+func (s *Solid) sampleSurface(n int, r *rand.Rand) []Vec3 {
+	if len(s.Triangles) == 0 {
+		return nil
+	}
+	// cumulative area by triangle
+	cumulative := make([]float64, len(s.Triangles))
+	var total float64
+	for i := range s.Triangles {
+		total += s.Triangles[i].Area()
+		cumulative[i] = total
+	}
+
+	points := make([]Vec3, n)
+	for k := range points {
+		i := r.Intn(len(s.Triangles))
+		if total > 0 {
+			x := r.Float64() * total
+			i = sort.SearchFloat64s(cumulative, x)
+			if i >= len(s.Triangles) {
+				i = len(s.Triangles) - 1
+			}
+		}
+		points[k] = s.Triangles[i].samplePoint(r.Float64(), r.Float64())
+	}
+	return points
+}
+
+// samplePoint maps u and v, uniformly distributed in [0, 1), to a point
+// uniformly distributed on the triangle.
+func (t *Triangle) samplePoint(u, v float64) Vec3 {
+	su := math.Sqrt(u)
+	a, b, c := 1-su, su*(1-v), su*v
+	return t.Vertices[0].MultScalar(a).Add(t.Vertices[1].MultScalar(b)).Add(t.Vertices[2].MultScalar(c))
+}
@@ -0,0 +1,78 @@
+package stl
+
+// Tests for the comparison of surfaces.
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCompareSurfaces(t *testing.T) {
+	a := makeBoxTestSolid(Vec3{2, 2, 2})
+	report := CompareSurfaces(a, makeBoxTestSolid(Vec3{2, 2, 2}), 1000)
+	if report.Hausdorff > 1e-12 || report.Mean > 1e-12 {
+		t.Errorf("Expected no deviation of equal solids, found %+v", report)
+	}
+
+	b := makeBoxTestSolid(Vec3{2, 2, 2.1})
+	report = CompareSurfaces(a, b, 5000)
+	if !almostEqual64(report.HausdorffAB, 0.1, 1e-9) || !almostEqual64(report.HausdorffBA, 0.1, 1e-9) ||
+		!almostEqual64(report.Hausdorff, 0.1, 1e-9) {
+		t.Errorf("Expected Hausdorff distance 0.1, found %+v", report)
+	}
+	if report.Mean <= 0 || report.RMS < report.Mean || report.RMS > report.Hausdorff {
+		t.Errorf("Expected 0 < mean <= RMS <= Hausdorff, found %+v", report)
+	}
+	total := 0
+	for _, count := range report.Histogram {
+		total += count
+	}
+	if len(report.Histogram) != DeviationHistogramBins || total != 10000 ||
+		!almostEqual64(report.HistogramBinWidth, 0.1/DeviationHistogramBins, 1e-9) {
+		t.Errorf("Expected histogram of 10000 samples, found %v with bin width %v", report.Histogram, report.HistogramBinWidth)
+	}
+	// most samples are on the bottom and sides, which are equal
+	if report.Histogram[0] < total/2 {
+		t.Errorf("Expected most samples in the first bin, found %v", report.Histogram)
+	}
+
+	if report := CompareSurfaces(a, &Solid{}, 100); report.Histogram != nil {
+		t.Errorf("Expected empty report, found %+v", report)
+	}
+}
+
+func TestBVHClosestPoint(t *testing.T) {
+	s := makeTorusTestSolid(2, 0.5, 24, 12)
+	tree := newBVH(s.Triangles)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		p := Vec3{4*r.Float64() - 2, 4*r.Float64() - 2, 2*r.Float64() - 1}
+		_, dist, triangle := tree.closestPoint(p)
+		best := -1.0
+		for j := range s.Triangles {
+			d := closestPointOnTriangle(p, &s.Triangles[j]).Diff(p).Len()
+			if best < 0 || d < best {
+				best = d
+			}
+		}
+		if !almostEqual64(dist, best, 1e-12) || triangle < 0 {
+			t.Errorf("Expected distance %v of %v, found %v in triangle %d", best, p, dist, triangle)
+		}
+	}
+
+	// the regions of a triangle
+	tri := Triangle{Vertices: [3]Vec3{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}}}
+	for _, c := range []struct{ p, expected Vec3 }{
+		{Vec3{0.5, 0.5, 1}, Vec3{0.5, 0.5, 0}},
+		{Vec3{-1, -1, 0}, Vec3{0, 0, 0}},
+		{Vec3{3, -1, 0}, Vec3{2, 0, 0}},
+		{Vec3{-1, 3, 0}, Vec3{0, 2, 0}},
+		{Vec3{1, -1, 0}, Vec3{1, 0, 0}},
+		{Vec3{-1, 1, 0}, Vec3{0, 1, 0}},
+		{Vec3{2, 2, 0}, Vec3{1, 1, 0}},
+	} {
+		if q := closestPointOnTriangle(c.p, &tri); !q.AlmostEqual(c.expected, 1e-12) {
+			t.Errorf("Expected closest point %v to %v, found %v", c.expected, c.p, q)
+		}
+	}
+}
@@ -58,6 +58,14 @@ rotated for 360° not to end up at exactly the original coordinates, but instead
 just very close to them. As the error is usually far smaller than the available
 precision of 3D printing applications, this is not an issue in most cases.
 
+Comparing Vertices
+
+A Solid stores every triangle with its own copy of its vertices, so
+triangles are only connected by vertices having the same position. Methods
+that don't take a tolerance, like FixOrientation, FindHoles,
+SelfIntersections and SplitNonManifold, compare vertices exactly. For
+files with rounding errors, call WeldVertices beforehand.
+
 Stream Processing
 
 You can implement the Writer interface to directly write into your own data structures.
@@ -66,5 +74,8 @@ This way you can use the CopyFile and CopyAll functions.
 	var ownData ownDataStructure // implements stl.Writer
 	err := stl.CopyFile("somefile.stl", &ownData)
 
+Files in an fs.FS, like embed.FS or a zip archive, can be read using
+ReadFS and CopyFS.
+
 */
 package stl
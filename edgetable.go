@@ -0,0 +1,140 @@
+package stl
+
+// This file contains compact lookup tables for the edges and faces of an
+// indexed mesh, using sorted arrays instead of maps.
+
+import (
+	"sort"
+)
+
+// tableEntry is an entry of a bucketTable, consisting of a key
+// and a face index.
+type tableEntry struct {
+	key  [2]uint32
+	face uint32
+}
+
+func (e *tableEntry) less(o *tableEntry) bool {
+	if e.key[0] != o.key[0] {
+		return e.key[0] < o.key[0]
+	}
+	if e.key[1] != o.key[1] {
+		return e.key[1] < o.key[1]
+	}
+	return e.face < o.face
+}
+
+// tableEntries implements sort.Interface.
+type tableEntries []tableEntry
+
+func (t tableEntries) Len() int           { return len(t) }
+func (t tableEntries) Less(i, j int) bool { return t[i].less(&t[j]) }
+func (t tableEntries) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+// sort sorts the entries by key and face, using insertion sort for the
+// small groups that are typical for meshes.
+func (t tableEntries) sort() {
+	if len(t) > 12 {
+		sort.Sort(t)
+		return
+	}
+	for i := 1; i < len(t); i++ {
+		for j := i; j > 0 && t[j].less(&t[j-1]); j-- {
+			t[j], t[j-1] = t[j-1], t[j]
+		}
+	}
+}
+
+// bucketTable groups entries by a vertex index, like a compressed sparse
+// row matrix. The entries of vertex v are entries[offsets[v]:offsets[v+1]],
+// sorted by key and face.
+type bucketTable struct {
+	offsets []int
+	entries tableEntries
+}
+
+// newBucketTable creates a table with count entries for each face, calling
+// fill to get the vertex and the key of entry k of face i.
+func newBucketTable(vertexCount, faceCount, count int, fill func(i, k int) (v uint32, key [2]uint32)) *bucketTable {
+	t := bucketTable{
+		offsets: make([]int, vertexCount+1),
+		entries: make(tableEntries, faceCount*count),
+	}
+	for i := 0; i < faceCount; i++ {
+		for k := 0; k < count; k++ {
+			v, _ := fill(i, k)
+			t.offsets[v+1]++
+		}
+	}
+	for v := 0; v < vertexCount; v++ {
+		t.offsets[v+1] += t.offsets[v]
+	}
+	next := append([]int(nil), t.offsets[:vertexCount]...)
+	for i := 0; i < faceCount; i++ {
+		for k := 0; k < count; k++ {
+			v, key := fill(i, k)
+			t.entries[next[v]] = tableEntry{key: key, face: uint32(i)}
+			next[v]++
+		}
+	}
+	for v := 0; v < vertexCount; v++ {
+		t.entries[t.offsets[v]:t.offsets[v+1]].sort()
+	}
+	return &t
+}
+
+// find returns the entries of vertex v with the given key.
+func (t *bucketTable) find(v uint32, key [2]uint32) tableEntries {
+	bucket := t.entries[t.offsets[v]:t.offsets[v+1]]
+	lo := sort.Search(len(bucket), func(i int) bool {
+		return bucket[i].key[0] > key[0] || (bucket[i].key[0] == key[0] && bucket[i].key[1] >= key[1])
+	})
+	hi := lo
+	for hi < len(bucket) && bucket[hi].key == key {
+		hi++
+	}
+	return bucket[lo:hi]
+}
+
+// newEdgeTable returns a table of the directed edges v -> w of the faces
+// of m, grouped by v with the key {w, 0}.
+func (m *IndexedMesh) newEdgeTable() *bucketTable {
+	return newBucketTable(len(m.Vertices), len(m.Faces), 3, func(i, e int) (uint32, [2]uint32) {
+		f := m.Faces[i]
+		return f[e], [2]uint32{f[(e+1)%3], 0}
+	})
+}
+
+// appendFacesWithEdge appends the faces other than i that contain the
+// directed edge v -> w to faces, each one only once.
+func (t *bucketTable) appendFacesWithEdge(faces []int, v, w uint32, i int) []int {
+	start := len(faces)
+	for _, entry := range t.find(v, [2]uint32{w, 0}) {
+		f := int(entry.face)
+		if f != i && (len(faces) == start || faces[len(faces)-1] != f) {
+			faces = append(faces, f)
+		}
+	}
+	return faces
+}
+
+// firstFaceWithEdge returns the lowest index of a face containing the edge
+// between v and w in any direction. The edge must be in the table.
+func (t *bucketTable) firstFaceWithEdge(v, w uint32) int {
+	first := -1
+	for _, entries := range [2]tableEntries{t.find(v, [2]uint32{w, 0}), t.find(w, [2]uint32{v, 0})} {
+		if len(entries) > 0 && (first < 0 || int(entries[0].face) < first) {
+			first = int(entries[0].face)
+		}
+	}
+	return first
+}
+
+// newFaceTable returns a table of the faces of m, grouped by the first vertex
+// of their faceKey, with the other two vertices as key.
+func (m *IndexedMesh) newFaceTable() *bucketTable {
+	return newBucketTable(len(m.Vertices), len(m.Faces), 1, func(i, _ int) (uint32, [2]uint32) {
+		key, _ := m.faceKey(i)
+		return key[0], [2]uint32{key[1], key[2]}
+	})
+}
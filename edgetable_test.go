@@ -0,0 +1,47 @@
+package stl
+
+// Tests for the edge and face lookup tables.
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEdgeTable(t *testing.T) {
+	m := makeTestSolid().ToIndexed(0)
+	m.Faces = append(m.Faces, m.Faces[0], [3]uint32{m.Faces[1][0], m.Faces[1][2], m.Faces[1][1]})
+	edges := m.newEdgeTable()
+	f := m.Faces[0]
+	if faces := edges.appendFacesWithEdge(nil, f[0], f[1], 0); !reflect.DeepEqual(faces, []int{4}) {
+		t.Errorf("Expected face 4 with same edge, found %v", faces)
+	}
+	if faces := edges.appendFacesWithEdge(nil, f[1], f[0], 0); len(faces) != 1 {
+		t.Errorf("Expected one face with counter edge, found %v", faces)
+	}
+	if first := edges.firstFaceWithEdge(f[1], f[0]); first != 0 {
+		t.Errorf("Expected first face 0, found %d", first)
+	}
+	if groups := m.duplicateFaces(); !reflect.DeepEqual(groups, [][]int{{0, 4}, {1, 5}}) {
+		t.Errorf("Expected duplicate groups [[0 4] [1 5]], found %v", groups)
+	}
+}
+
+func TestValidateWithOptions(t *testing.T) {
+	for _, s := range []*Solid{makeBrokenTestSolid(), makeBowtieTestSolid(), makeTJunctionTestSolid()} {
+		expected := s.Validate()
+		for _, workers := range []int{2, 3, 16} {
+			if errors := s.ValidateWithOptions(ValidateOptions{Workers: workers}); !reflect.DeepEqual(errors, expected) {
+				t.Errorf("Expected same result with %d workers, found %v instead of %v", workers, errors, expected)
+			}
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	s := makeTorusTestSolid(2, 0.5, 200, 100)
+	s.WeldVertices(0.000001)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Validate()
+	}
+}
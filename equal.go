@@ -0,0 +1,202 @@
+package stl
+
+// This file contains the comparison and hashing of solids independently
+// of the order of their triangles.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// Equal is true if s and o consist of the same triangles, regardless of
+// their order in Solid.Triangles and of the cyclic rotation of the vertices
+// within a triangle. The orientation of the triangles must be the same.
+// Coordinates may differ by up to tol. The attributes of the triangles must
+// be equal, while their normals, and the name, header and format of the
+// solids are ignored. Every triangle of o must match a different triangle of
+// s. If a triangle is within tol of several ones, a matching of all triangles
+// is searched for, so s.Equal(o, tol) is the same as o.Equal(s, tol).
+func (s *Solid) Equal(o *Solid, tol float64) bool {
+	if len(s.Triangles) != len(o.Triangles) {
+		return false
+	}
+
+	// find candidates for each triangle of o by the centroid of s's triangles
+	cellSize := weldCellFactor * tol
+	cell := func(p Vec3) [3]int64 {
+		if tol <= 0 {
+			return [3]int64{int64(math.Float64bits(p[0])), int64(math.Float64bits(p[1])), int64(math.Float64bits(p[2]))}
+		}
+		return [3]int64{
+			cellCoordinate(p[0], cellSize),
+			cellCoordinate(p[1], cellSize),
+			cellCoordinate(p[2], cellSize),
+		}
+	}
+	cells := make(map[[3]int64][]int, len(s.Triangles))
+	for i := range s.Triangles {
+		t := s.Triangles[i].canonical()
+		c := cell(t.Centroid())
+		cells[c] = append(cells[c], i)
+	}
+
+	// all triangles of s each triangle of o could be matched with
+	candidates := make([][]int, len(o.Triangles))
+	for j := range o.Triangles {
+		t := o.Triangles[j].canonical()
+		centroid := t.Centroid()
+		lo, hi := cell(centroid), cell(centroid)
+		if tol > 0 {
+			lo, hi = cell(centroid.Diff(Vec3{tol, tol, tol})), cell(centroid.Add(Vec3{tol, tol, tol}))
+		}
+		for x := lo[0]; x <= hi[0]; x++ {
+			for y := lo[1]; y <= hi[1]; y++ {
+				for z := lo[2]; z <= hi[2]; z++ {
+					for _, i := range cells[[3]int64{x, y, z}] {
+						if s.Triangles[i].rotatedAlmostEqual(&t, tol) {
+							candidates[j] = append(candidates[j], i)
+						}
+					}
+				}
+			}
+		}
+		if len(candidates[j]) == 0 {
+			return false
+		}
+	}
+	return hasPerfectMatching(candidates, len(s.Triangles))
+}
+
+// hasPerfectMatching is true if each of the items 0..len(candidates)-1 can be
+// assigned a different one of the items 0..n-1 listed in its candidates.
+// After assigning the first free candidate to every item, the remaining
+// items are assigned using augmenting paths.
+func hasPerfectMatching(candidates [][]int, n int) bool {
+	matchOf := make([]int, n) // item assigned to each candidate, or -1
+	for i := range matchOf {
+		matchOf[i] = -1
+	}
+	var unmatched []int
+	for j, c := range candidates {
+		found := false
+		for _, i := range c {
+			if matchOf[i] < 0 {
+				matchOf[i] = j
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, j)
+		}
+	}
+
+	// visited marks the candidates seen while searching a path for the
+	// k-th unmatched item with k+1
+	visited := make([]int, n)
+	var augment func(j, mark int) bool
+	augment = func(j, mark int) bool {
+		for _, i := range candidates[j] {
+			if visited[i] == mark {
+				continue
+			}
+			visited[i] = mark
+			if matchOf[i] < 0 || augment(matchOf[i], mark) {
+				matchOf[i] = j
+				return true
+			}
+		}
+		return false
+	}
+	for k, j := range unmatched {
+		if !augment(j, k+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanonicalHash returns a SHA-256 hash of the triangles of the solid that
+// does not depend on their order and the cyclic rotation of their vertices.
+// Solids with the same hash are Equal with tolerance 0. Like in Equal,
+// normals, name, header and format are not part of the hash.
+func (s *Solid) CanonicalHash() [sha256.Size]byte {
+	const recordSize = 9*8 + 2
+	records := make([]byte, recordSize*len(s.Triangles))
+	for i := range s.Triangles {
+		t := s.Triangles[i].canonical()
+		record := records[i*recordSize : (i+1)*recordSize]
+		for v := 0; v < 3; v++ {
+			for d := 0; d < 3; d++ {
+				binary.LittleEndian.PutUint64(record[8*(3*v+d):], math.Float64bits(t.Vertices[v][d]))
+			}
+		}
+		binary.LittleEndian.PutUint16(record[9*8:], t.Attributes)
+	}
+
+	order := make([]int, len(s.Triangles))
+	for i := range order {
+		order[i] = i
+	}
+	record := func(i int) []byte {
+		return records[i*recordSize : (i+1)*recordSize]
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return bytes.Compare(record(order[i]), record(order[j])) < 0
+	})
+
+	h := sha256.New()
+	for _, i := range order {
+		h.Write(record(i))
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// canonical returns the triangle with its vertices rotated so that the
+// lexicographically smallest one is the first. Negative zero coordinates
+// are replaced by zero.
+func (t Triangle) canonical() Triangle {
+	for v := 0; v < 3; v++ {
+		for d := 0; d < 3; d++ {
+			t.Vertices[v][d] += 0 // -0 + 0 == +0
+		}
+	}
+	less := func(a, b Vec3) bool {
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return a[2] < b[2]
+	}
+	first := 0
+	for v := 1; v < 3; v++ {
+		if less(t.Vertices[v], t.Vertices[first]) {
+			first = v
+		}
+	}
+	t.Vertices = [3]Vec3{t.Vertices[first], t.Vertices[(first+1)%3], t.Vertices[(first+2)%3]}
+	return t
+}
+
+// rotatedAlmostEqual is true if o equals t within tol after a cyclic
+// rotation of its vertices, and has the same attributes.
+func (t *Triangle) rotatedAlmostEqual(o *Triangle, tol float64) bool {
+	if t.Attributes != o.Attributes {
+		return false
+	}
+	for r := 0; r < 3; r++ {
+		if t.Vertices[0].AlmostEqual(o.Vertices[r], tol) &&
+			t.Vertices[1].AlmostEqual(o.Vertices[(r+1)%3], tol) &&
+			t.Vertices[2].AlmostEqual(o.Vertices[(r+2)%3], tol) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package stl
+
+// Tests for the order-independent comparison and hashing of solids.
+
+import (
+	"testing"
+)
+
+// shuffledTestSolid returns the test solid with reversed triangle order and
+// rotated vertices.
+func shuffledTestSolid() *Solid {
+	s := makeTestSolid()
+	n := len(s.Triangles)
+	for i := 0; i < n/2; i++ {
+		s.Triangles[i], s.Triangles[n-1-i] = s.Triangles[n-1-i], s.Triangles[i]
+	}
+	for i := range s.Triangles {
+		v := s.Triangles[i].Vertices
+		s.Triangles[i].Vertices = [3]Vec3{v[i%3], v[(i+1)%3], v[(i+2)%3]}
+	}
+	s.Name = "Shuffled"
+	return s
+}
+
+func TestEqual(t *testing.T) {
+	s := makeTestSolid()
+	shuffled := shuffledTestSolid()
+	if !s.Equal(shuffled, 0) || !shuffled.Equal(s, 0) {
+		t.Error("Expected shuffled solid to be equal")
+	}
+
+	moved := shuffledTestSolid()
+	moved.Translate(Vec3{0.0001, 0, 0})
+	if s.Equal(moved, 0) || s.Equal(moved, 0.00001) {
+		t.Error("Expected moved solid not to be equal within 0.00001")
+	}
+	if !s.Equal(moved, 0.001) {
+		t.Error("Expected moved solid to be equal within 0.001")
+	}
+
+	flipped := makeTestSolid()
+	flipped.Triangles[0].flip()
+	if s.Equal(flipped, 0.001) {
+		t.Error("Expected solid with flipped triangle not to be equal")
+	}
+
+	withAttributes := makeTestSolid()
+	withAttributes.Triangles[1].Attributes = 7
+	if s.Equal(withAttributes, 0) {
+		t.Error("Expected solid with different attributes not to be equal")
+	}
+
+	// the same triangle twice does not match two different ones
+	doubled := makeTestSolid()
+	doubled.Triangles[1] = doubled.Triangles[0]
+	if s.Equal(doubled, 0) || doubled.Equal(s, 0) {
+		t.Error("Expected solid with duplicate triangle not to be equal")
+	}
+
+	if s.Equal(&Solid{}, 0) || !(&Solid{}).Equal(&Solid{}, 0) {
+		t.Error("Expected only empty solids to be equal to an empty solid")
+	}
+}
+
+func TestEqual_NearDuplicates(t *testing.T) {
+	triangleAt := func(x float64) Triangle {
+		return Triangle{Vertices: [3]Vec3{{x, 0, 0}, {x + 4, 0, 0}, {x, 4, 0}}}
+	}
+	solidAt := func(xs ...float64) *Solid {
+		var s Solid
+		for _, x := range xs {
+			s.AppendTriangle(triangleAt(x))
+		}
+		return &s
+	}
+	for _, test := range []struct {
+		a, b  *Solid
+		equal bool
+	}{
+		// the first triangle of b is close to both of a, but must be
+		// matched with the second one
+		{a: solidAt(0, 1.5), b: solidAt(1, -0.5), equal: true},
+		{a: solidAt(0, 1.5, 3), b: solidAt(1, 2.5, -0.5), equal: true},
+		{a: solidAt(0, 1.5), b: solidAt(-0.5, -0.75), equal: false},
+	} {
+		if test.a.Equal(test.b, 1) != test.equal || test.b.Equal(test.a, 1) != test.equal {
+			t.Errorf("Expected %v for both directions, found %v and %v",
+				test.equal, test.a.Equal(test.b, 1), test.b.Equal(test.a, 1))
+		}
+	}
+}
+
+func TestEqual_TinyTolerance(t *testing.T) {
+	// the cell coordinates exceed the range of int64
+	s := makeTestSolid()
+	s.Translate(Vec3{1e6, -1e6, 0})
+	if shuffled := shuffledTestSolid(); !s.Equal(s, 1e-20) || s.Equal(shuffled, 1e-20) {
+		t.Error("Expected only the same solid to be equal with tiny tolerance")
+	}
+}
+
+func TestCanonicalHash(t *testing.T) {
+	s := makeTestSolid()
+	hash := s.CanonicalHash()
+	if shuffled := shuffledTestSolid(); shuffled.CanonicalHash() != hash {
+		t.Error("Expected same hash for shuffled solid")
+	}
+
+	negativeZero := makeTestSolid()
+	negativeZero.Triangles[0].Vertices[0][0] = -negativeZero.Triangles[0].Vertices[0][0]
+	if negativeZero.CanonicalHash() != hash {
+		t.Error("Expected same hash for negative zero coordinate")
+	}
+
+	for _, modify := range []func(s *Solid){
+		func(s *Solid) { s.Triangles[0].flip() },
+		func(s *Solid) { s.Triangles[2].Vertices[1][2] += 0.000001 },
+		func(s *Solid) { s.Triangles[3].Attributes = 1 },
+		func(s *Solid) { s.Triangles = s.Triangles[1:] },
+	} {
+		o := makeTestSolid()
+		modify(o)
+		if o.CanonicalHash() == hash {
+			t.Errorf("Expected different hash for %v", o.Triangles)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package stl
+
+// This file provides the HalfEdgeMesh data type for adjacency queries
+// on an IndexedMesh.
+
+// HalfEdgeMesh provides constant time adjacency queries on an IndexedMesh.
+//
+// Every face f of the mesh has three half-edges with the indices 3*f, 3*f+1
+// and 3*f+2. Half-edge 3*f+k runs from vertex Faces[f][k] to vertex
+// Faces[f][(k+1)%3], so its index within the face equals the edge index used
+// in TriangleErrors.EdgeErrors. Two half-edges running between the same
+// vertices in opposite directions are twins, and their faces are neighbors.
+//
+// Configurations that cannot be represented by half-edges, i.e. edges used
+// by more than two faces, edges used twice in the same direction because
+// of inconsistent orientation, and edges from a vertex to itself,
+// are detected during construction. Their half-edges remain without twin,
+// and are listed in NonManifoldEdges. Vertices where more than one fan
+// of faces meet are listed in NonManifoldVertices.
+//
+// The HalfEdgeMesh does not notice changes of Mesh.Faces, so it must be
+// rebuilt after the faces have been changed.
+type HalfEdgeMesh struct {
+	// Mesh is the underlying mesh.
+	Mesh *IndexedMesh
+
+	// NonManifoldEdges are the half-edges that could not be paired
+	// although they are not simple boundary edges.
+	NonManifoldEdges []int
+
+	// NonManifoldVertices are the indices of vertices with more than one
+	// fan of faces around them.
+	NonManifoldVertices []uint32
+
+	twin       []int // twin half-edge, or noTwin or nonManifoldTwin
+	fanOffsets []int // fans of vertex v are fans[fanOffsets[v]:fanOffsets[v+1]]
+	fans       []int // first outgoing half-edge of each fan
+}
+
+// Special values for HalfEdgeMesh.twin
+const (
+	noTwin          = -1
+	nonManifoldTwin = -2
+)
+
+// NewHalfEdgeMesh builds the half-edge structure for m.
+func NewHalfEdgeMesh(m *IndexedMesh) *HalfEdgeMesh {
+	hm := HalfEdgeMesh{
+		Mesh: m,
+		twin: make([]int, 3*len(m.Faces)),
+	}
+	hm.pairHalfEdges()
+	hm.buildFans()
+	return &hm
+}
+
+// ToHalfEdge converts the solid into a HalfEdgeMesh, welding vertices like
+// ToIndexed.
+func (s *Solid) ToHalfEdge(weldTolerance float64) *HalfEdgeMesh {
+	return NewHalfEdgeMesh(s.ToIndexed(weldTolerance))
+}
+
+func (hm *HalfEdgeMesh) pairHalfEdges() {
+	edgeToHalfEdges := make(map[[2]uint32][]int, len(hm.twin))
+	for h := range hm.twin {
+		key := [2]uint32{hm.Origin(h), hm.Target(h)}
+		edgeToHalfEdges[key] = append(edgeToHalfEdges[key], h)
+	}
+	for h := range hm.twin {
+		origin, target := hm.Origin(h), hm.Target(h)
+		same := edgeToHalfEdges[[2]uint32{origin, target}]
+		counter := edgeToHalfEdges[[2]uint32{target, origin}]
+		switch {
+		case origin == target || len(same) > 1 || len(counter) > 1:
+			hm.twin[h] = nonManifoldTwin
+			hm.NonManifoldEdges = append(hm.NonManifoldEdges, h)
+		case len(counter) == 1:
+			hm.twin[h] = counter[0]
+		default:
+			hm.twin[h] = noTwin
+		}
+	}
+}
+
+// buildFans groups the outgoing half-edges of every vertex into fans of
+// faces connected by twins.
+func (hm *HalfEdgeMesh) buildFans() {
+	vertexCount := len(hm.Mesh.Vertices)
+
+	// collect outgoing half-edges by vertex
+	outgoingOffsets := make([]int, vertexCount+1)
+	for h := range hm.twin {
+		outgoingOffsets[hm.Origin(h)+1]++
+	}
+	for v := 0; v < vertexCount; v++ {
+		outgoingOffsets[v+1] += outgoingOffsets[v]
+	}
+	outgoing := make([]int, len(hm.twin))
+	fill := make([]int, vertexCount)
+	copy(fill, outgoingOffsets)
+	for h := range hm.twin {
+		v := hm.Origin(h)
+		outgoing[fill[v]] = h
+		fill[v]++
+	}
+
+	visited := make([]bool, len(hm.twin))
+	hm.fanOffsets = make([]int, vertexCount+1)
+	for v := 0; v < vertexCount; v++ {
+		hm.fanOffsets[v] = len(hm.fans)
+		for _, h := range outgoing[outgoingOffsets[v]:outgoingOffsets[v+1]] {
+			if visited[h] {
+				continue
+			}
+			start := hm.fanStart(h)
+			hm.fans = append(hm.fans, start)
+			for g := start; g >= 0 && !visited[g]; g = hm.nextAround(g) {
+				visited[g] = true
+			}
+		}
+		if hm.fanOffsets[v]+1 < len(hm.fans) {
+			hm.NonManifoldVertices = append(hm.NonManifoldVertices, uint32(v))
+		}
+	}
+	hm.fanOffsets[vertexCount] = len(hm.fans)
+}
+
+// nextAround returns the next outgoing half-edge around the origin of h,
+// or -1 if the fan ends here.
+func (hm *HalfEdgeMesh) nextAround(h int) int {
+	return hm.Twin(hm.Prev(h))
+}
+
+// prevAround returns the previous outgoing half-edge around the origin of h,
+// or -1 if the fan ends here.
+func (hm *HalfEdgeMesh) prevAround(h int) int {
+	t := hm.Twin(h)
+	if t < 0 {
+		return -1
+	}
+	return hm.Next(t)
+}
+
+// fanStart returns the first outgoing half-edge of the fan containing h.
+// For closed fans this is h itself.
+func (hm *HalfEdgeMesh) fanStart(h int) int {
+	start := h
+	for g := hm.prevAround(h); g >= 0 && g != h; g = hm.prevAround(g) {
+		start = g
+	}
+	if hm.prevAround(start) == h {
+		return h
+	}
+	return start
+}
+
+// NumHalfEdges returns the number of half-edges, being 3 * len(Mesh.Faces).
+func (hm *HalfEdgeMesh) NumHalfEdges() int {
+	return len(hm.twin)
+}
+
+// Face returns the index of the face half-edge h belongs to.
+func (hm *HalfEdgeMesh) Face(h int) int {
+	return h / 3
+}
+
+// Next returns the next half-edge within the same face.
+func (hm *HalfEdgeMesh) Next(h int) int {
+	if h%3 == 2 {
+		return h - 2
+	}
+	return h + 1
+}
+
+// Prev returns the previous half-edge within the same face.
+func (hm *HalfEdgeMesh) Prev(h int) int {
+	if h%3 == 0 {
+		return h + 2
+	}
+	return h - 1
+}
+
+// Origin returns the vertex index half-edge h starts at.
+func (hm *HalfEdgeMesh) Origin(h int) uint32 {
+	return hm.Mesh.Faces[h/3][h%3]
+}
+
+// Target returns the vertex index half-edge h ends at.
+func (hm *HalfEdgeMesh) Target(h int) uint32 {
+	return hm.Mesh.Faces[h/3][(h+1)%3]
+}
+
+// Twin returns the half-edge running in the opposite direction in the
+// neighboring face, or -1 if there is none.
+func (hm *HalfEdgeMesh) Twin(h int) int {
+	if hm.twin[h] < 0 {
+		return -1
+	}
+	return hm.twin[h]
+}
+
+// IsBoundary is true if half-edge h has no twin, and is not a non-manifold edge.
+func (hm *HalfEdgeMesh) IsBoundary(h int) bool {
+	return hm.twin[h] == noTwin
+}
+
+// OppositeFace returns the face on the other side of edge e of face f,
+// with e being the edge index like in TriangleErrors.EdgeErrors. Returns false
+// if there is no such face.
+func (hm *HalfEdgeMesh) OppositeFace(f, e int) (int, bool) {
+	t := hm.Twin(3*f + e)
+	if t < 0 {
+		return -1, false
+	}
+	return hm.Face(t), true
+}
+
+// OneRing calls fn for every half-edge going out from vertex v, in the order
+// of the faces around v, until fn returns false. For non-manifold vertices
+// the fans of faces are visited one after another.
+func (hm *HalfEdgeMesh) OneRing(v uint32, fn func(h int) bool) {
+	for _, start := range hm.fans[hm.fanOffsets[v]:hm.fanOffsets[v+1]] {
+		h := start
+		for {
+			if !fn(h) {
+				return
+			}
+			h = hm.nextAround(h)
+			if h < 0 || h == start {
+				break
+			}
+		}
+	}
+}
+
+// Neighbors returns the indices of the vertices connected to v by an edge.
+func (hm *HalfEdgeMesh) Neighbors(v uint32) []uint32 {
+	var r []uint32
+	seen := make(map[uint32]bool)
+	add := func(w uint32) {
+		if !seen[w] && w != v {
+			seen[w] = true
+			r = append(r, w)
+		}
+	}
+	hm.OneRing(v, func(h int) bool {
+		add(hm.Target(h))
+		if hm.nextAround(h) < 0 {
+			// the fan is open, the last edge is an incoming one
+			add(hm.Origin(hm.Prev(h)))
+		}
+		return true
+	})
+	return r
+}
+
+// Valence returns the number of edges connected to vertex v.
+func (hm *HalfEdgeMesh) Valence(v uint32) int {
+	return len(hm.Neighbors(v))
+}
+
+// VertexFaces calls fn for every face around vertex v until fn returns false.
+func (hm *HalfEdgeMesh) VertexFaces(v uint32, fn func(f int) bool) {
+	hm.OneRing(v, func(h int) bool {
+		return fn(hm.Face(h))
+	})
+}
+
+// IsBoundaryVertex is true if v is at the end of a boundary edge.
+func (hm *HalfEdgeMesh) IsBoundaryVertex(v uint32) bool {
+	isBoundary := false
+	hm.OneRing(v, func(h int) bool {
+		isBoundary = hm.nextAround(h) < 0 || hm.IsBoundary(h)
+		return !isBoundary
+	})
+	return isBoundary
+}
+
+// nextBoundary returns the boundary half-edge following the boundary
+// half-edge h along the hole, or -1 if the way is blocked by a non-manifold edge.
+func (hm *HalfEdgeMesh) nextBoundary(h int) int {
+	g := hm.Next(h)
+	for i := 0; i < len(hm.twin); i++ {
+		switch hm.twin[g] {
+		case noTwin:
+			return g
+		case nonManifoldTwin:
+			return -1
+		}
+		g = hm.Next(hm.twin[g])
+	}
+	return -1
+}
+
+// WalkBoundary calls fn for every boundary half-edge of the boundary loop
+// containing the boundary half-edge h, starting with h, until fn returns
+// false or the loop is closed. The half-edges belong to the faces next to
+// the hole, so the loop runs in opposite direction of a face closing the
+// hole.
+func (hm *HalfEdgeMesh) WalkBoundary(h int, fn func(h int) bool) {
+	if !hm.IsBoundary(h) {
+		return
+	}
+	g := h
+	for i := 0; i < len(hm.twin); i++ {
+		if !fn(g) {
+			return
+		}
+		g = hm.nextBoundary(g)
+		if g < 0 || g == h {
+			return
+		}
+	}
+}
+
+// BoundaryLoops returns all boundary loops as lists of half-edges,
+// see WalkBoundary. Non-manifold half-edges are not part of any loop.
+func (hm *HalfEdgeMesh) BoundaryLoops() [][]int {
+	visited := make([]bool, len(hm.twin))
+	var loops [][]int
+	for h := range hm.twin {
+		if visited[h] || !hm.IsBoundary(h) {
+			continue
+		}
+		var loop []int
+		hm.WalkBoundary(h, func(g int) bool {
+			if visited[g] {
+				return false
+			}
+			visited[g] = true
+			loop = append(loop, g)
+			return true
+		})
+		loops = append(loops, loop)
+	}
+	return loops
+}
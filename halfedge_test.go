@@ -0,0 +1,103 @@
+package stl
+
+// Tests for the HalfEdgeMesh data type.
+
+import (
+	"testing"
+)
+
+func TestHalfEdgeMesh_Closed(t *testing.T) {
+	hm := makeTestSolid().ToHalfEdge(0)
+	if len(hm.NonManifoldEdges) != 0 || len(hm.NonManifoldVertices) != 0 {
+		t.Errorf("Expected manifold mesh, found non-manifold edges %v and vertices %v",
+			hm.NonManifoldEdges, hm.NonManifoldVertices)
+	}
+	if loops := hm.BoundaryLoops(); len(loops) != 0 {
+		t.Errorf("Expected no boundary loops, found %v", loops)
+	}
+	for h := 0; h < hm.NumHalfEdges(); h++ {
+		twin := hm.Twin(h)
+		if twin < 0 {
+			t.Errorf("Half-edge %d has no twin", h)
+			continue
+		}
+		if hm.Twin(twin) != h || hm.Origin(twin) != hm.Target(h) || hm.Target(twin) != hm.Origin(h) {
+			t.Errorf("Half-edge %d and twin %d do not match", h, twin)
+		}
+	}
+	for v := range hm.Mesh.Vertices {
+		if valence := hm.Valence(uint32(v)); valence != 3 {
+			t.Errorf("Expected valence 3 for vertex %d, found %d", v, valence)
+		}
+		faceCount := 0
+		hm.VertexFaces(uint32(v), func(f int) bool {
+			faceCount++
+			return true
+		})
+		if faceCount != 3 {
+			t.Errorf("Expected 3 faces around vertex %d, found %d", v, faceCount)
+		}
+	}
+	// Triangle 0 and triangle 3 share the edge {0, 0, 0} -> {0, 1, 0}
+	if f, found := hm.OppositeFace(0, 0); !found || f != 3 {
+		t.Errorf("Expected face 3 opposite to edge 0 of face 0, found %d", f)
+	}
+}
+
+func TestHalfEdgeMesh_Open(t *testing.T) {
+	s := makeTestSolid()
+	s.Triangles = s.Triangles[1:]
+	hm := s.ToHalfEdge(0)
+	if len(hm.NonManifoldEdges) != 0 || len(hm.NonManifoldVertices) != 0 {
+		t.Errorf("Expected manifold mesh, found non-manifold edges %v and vertices %v",
+			hm.NonManifoldEdges, hm.NonManifoldVertices)
+	}
+	loops := hm.BoundaryLoops()
+	if len(loops) != 1 || len(loops[0]) != 3 {
+		t.Fatalf("Expected one boundary loop of 3 edges, found %v", loops)
+	}
+	for i, h := range loops[0] {
+		next := loops[0][(i+1)%3]
+		if hm.Target(h) != hm.Origin(next) {
+			t.Errorf("Boundary loop is not connected at half-edge %d", h)
+		}
+	}
+	for v := range hm.Mesh.Vertices {
+		if valence := hm.Valence(uint32(v)); valence != 3 {
+			t.Errorf("Expected valence 3 for vertex %d, found %d", v, valence)
+		}
+	}
+	if !hm.IsBoundaryVertex(hm.Origin(loops[0][0])) {
+		t.Error("Expected boundary vertex")
+	}
+}
+
+func TestHalfEdgeMesh_NonManifold(t *testing.T) {
+	// Two triangles touching in vertex 0 only
+	bowtie := &IndexedMesh{
+		Vertices: []Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {-1, 0, 0}, {-1, -1, 0}},
+		Faces:    [][3]uint32{{0, 1, 2}, {0, 3, 4}},
+	}
+	hm := NewHalfEdgeMesh(bowtie)
+	if len(hm.NonManifoldVertices) != 1 || hm.NonManifoldVertices[0] != 0 {
+		t.Errorf("Expected non-manifold vertex 0, found %v", hm.NonManifoldVertices)
+	}
+	if valence := hm.Valence(0); valence != 4 {
+		t.Errorf("Expected valence 4 for vertex 0, found %d", valence)
+	}
+
+	// Three triangles sharing the edge 0 - 1
+	fin := &IndexedMesh{
+		Vertices: []Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}},
+		Faces:    [][3]uint32{{0, 1, 2}, {1, 0, 3}, {1, 0, 4}},
+	}
+	hm = NewHalfEdgeMesh(fin)
+	if len(hm.NonManifoldEdges) != 3 {
+		t.Errorf("Expected 3 non-manifold half-edges, found %v", hm.NonManifoldEdges)
+	}
+	for _, h := range hm.NonManifoldEdges {
+		if hm.IsBoundary(h) || hm.Twin(h) >= 0 {
+			t.Errorf("Non-manifold half-edge %d must neither be boundary nor have a twin", h)
+		}
+	}
+}
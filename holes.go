@@ -0,0 +1,349 @@
+package stl
+
+// This file contains the detection and filling of holes.
+
+import (
+	"math"
+)
+
+// Hole is a closed loop of boundary edges, i.e. edges that belong to
+// only one triangle, see Solid.FindHoles.
+type Hole struct {
+	// Vertices of the loop, in the order that a triangle closing the hole
+	// would use them, i.e. counter-clockwise when looking from outside.
+	Vertices []Vec3
+
+	// Triangles contains for each edge Vertices[i] -> Vertices[(i+1)%n]
+	// the index of the triangle in Solid.Triangles or IndexedMesh.Faces on
+	// the other side of it.
+	Triangles []int
+}
+
+// Perimeter returns the sum of the edge lengths of the hole.
+func (h *Hole) Perimeter() float64 {
+	var perimeter float64
+	for i, v := range h.Vertices {
+		perimeter += h.Vertices[(i+1)%len(h.Vertices)].Diff(v).Len()
+	}
+	return perimeter
+}
+
+// FindHoles returns all holes of the solid. Only boundary loops that can be
+// followed in a consistent direction, and that do not run through
+// non-manifold edges, are found. Fix the orientation of the triangles using
+// FixOrientation first to find all of them.
+func (s *Solid) FindHoles() []Hole {
+	holes, _ := s.ToHalfEdge(0).holes()
+	return holes
+}
+
+// FindHoles returns all holes of the mesh like Solid.FindHoles, with
+// Hole.Triangles referring to m.Faces.
+func (m *IndexedMesh) FindHoles() []Hole {
+	holes, _ := NewHalfEdgeMesh(m).holes()
+	return holes
+}
+
+// holes returns the holes of the mesh, and for each of them the indices of
+// its vertices in hm.Mesh.Vertices.
+func (hm *HalfEdgeMesh) holes() ([]Hole, [][]uint32) {
+	loops := hm.closedBoundaryLoops()
+	holes := make([]Hole, len(loops))
+	indices := make([][]uint32, len(loops))
+	for i, loop := range loops {
+		n := len(loop)
+		holes[i].Vertices = make([]Vec3, n)
+		holes[i].Triangles = make([]int, n)
+		indices[i] = make([]uint32, n)
+		// the half-edges run in the opposite direction of a closing face
+		for j, h := range loop {
+			indices[i][n-1-j] = hm.Origin(h)
+			holes[i].Vertices[n-1-j] = hm.Mesh.Vertices[hm.Origin(h)]
+			holes[i].Triangles[(2*n-2-j)%n] = hm.Face(h)
+		}
+	}
+	return holes, indices
+}
+
+// closedBoundaryLoops returns the boundary loops that are actually closed.
+func (hm *HalfEdgeMesh) closedBoundaryLoops() [][]int {
+	var loops [][]int
+	for _, loop := range hm.BoundaryLoops() {
+		if len(loop) >= 3 && hm.Target(loop[len(loop)-1]) == hm.Origin(loop[0]) {
+			loops = append(loops, loop)
+		}
+	}
+	return loops
+}
+
+// HoleFillMethod selects the triangulation used by Solid.FillHoles.
+type HoleFillMethod int
+
+const (
+	// FillFan connects the first vertex of the hole to all other ones.
+	// This is fast, but only produces good results for convex holes.
+	FillFan HoleFillMethod = iota
+
+	// FillMinimumArea chooses the triangulation with the smallest
+	// total area. It takes O(n³) time for a hole with n edges.
+	// Holes with more than maxMinimumAreaEdges edges are filled using
+	// FillFan instead.
+	FillMinimumArea
+)
+
+// maxMinimumAreaEdges limits the size of holes filled with FillMinimumArea.
+const maxMinimumAreaEdges = 500
+
+// Parameters of the smooth fill
+const (
+	smoothFillRefinements = 2
+	smoothFillIterations  = 50
+)
+
+// FillHolesOptions configures Solid.FillHoles.
+type FillHolesOptions struct {
+	// Method is the triangulation method.
+	Method HoleFillMethod
+
+	// Smooth refines the triangulation by inserting new vertices, which are
+	// then moved to form a smooth membrane spanned by the hole's boundary.
+	// This is suitable for holes in curved surfaces.
+	Smooth bool
+
+	// MaxEdges is the maximum number of edges of a hole to be filled.
+	// Larger holes are left alone. 0 means no limit.
+	MaxEdges int
+
+	// MaxPerimeter is the maximum perimeter of a hole to be filled.
+	// Larger holes are left alone. 0 means no limit.
+	MaxPerimeter float64
+}
+
+// FillHoles closes the holes found by FindHoles with new triangles. Returns
+// the number of holes filled.
+func (s *Solid) FillHoles(opts FillHolesOptions) int {
+	filled := 0
+	for _, hole := range s.FindHoles() {
+		vertices, faces, ok := hole.patch(opts)
+		if !ok {
+			continue
+		}
+		for _, f := range faces {
+			t := Triangle{Vertices: [3]Vec3{vertices[f[0]], vertices[f[1]], vertices[f[2]]}}
+			t.recalculateNormal()
+			s.AppendTriangle(t)
+		}
+		filled++
+	}
+	return filled
+}
+
+// FillHoles closes the holes of the mesh like Solid.FillHoles. The new faces
+// use the vertices of the holes, and vertices inserted by
+// FillHolesOptions.Smooth are appended to m.Vertices. Returns the number of
+// holes filled.
+func (m *IndexedMesh) FillHoles(opts FillHolesOptions) int {
+	hasNormals := len(m.Normals) == len(m.Faces)
+	hasAttributes := len(m.Attributes) == len(m.Faces)
+	holes, indices := NewHalfEdgeMesh(m).holes()
+	filled := 0
+	for h := range holes {
+		vertices, faces, ok := holes[h].patch(opts)
+		if !ok {
+			continue
+		}
+		index := indices[h]
+		for _, v := range vertices[len(index):] {
+			index = append(index, uint32(len(m.Vertices)))
+			m.Vertices = append(m.Vertices, v)
+		}
+		for _, f := range faces {
+			m.Faces = append(m.Faces, [3]uint32{index[f[0]], index[f[1]], index[f[2]]})
+			if hasNormals {
+				t := Triangle{Vertices: [3]Vec3{vertices[f[0]], vertices[f[1]], vertices[f[2]]}}
+				m.Normals = append(m.Normals, t.CalculatedNormal())
+			}
+			if hasAttributes {
+				m.Attributes = append(m.Attributes, 0)
+			}
+		}
+		filled++
+	}
+	return filled
+}
+
+// patch returns the vertices and faces closing the hole as selected by opts,
+// with the vertices starting with h.Vertices. ok is false if the hole is too
+// large to be filled.
+func (h *Hole) patch(opts FillHolesOptions) (vertices []Vec3, faces [][3]int, ok bool) {
+	n := len(h.Vertices)
+	if (opts.MaxEdges > 0 && n > opts.MaxEdges) ||
+		(opts.MaxPerimeter > 0 && h.Perimeter() > opts.MaxPerimeter) {
+		return nil, nil, false
+	}
+
+	if opts.Method == FillMinimumArea && n <= maxMinimumAreaEdges {
+		faces = triangulateMinimumArea(h.Vertices)
+	} else {
+		faces = triangulateFan(n)
+	}
+	vertices = h.Vertices
+	if opts.Smooth {
+		vertices, faces = smoothPatch(vertices, n, faces)
+	}
+	return vertices, faces, true
+}
+
+// triangulateFan triangulates a polygon with n vertices by connecting
+// vertex 0 with all other ones.
+func triangulateFan(n int) [][3]int {
+	faces := make([][3]int, 0, n-2)
+	for i := 1; i < n-1; i++ {
+		faces = append(faces, [3]int{0, i, i + 1})
+	}
+	return faces
+}
+
+// triangulateMinimumArea triangulates the polygon p choosing the
+// triangulation with the smallest total area using dynamic programming.
+func triangulateMinimumArea(p []Vec3) [][3]int {
+	n := len(p)
+	// weight[i][j] is the minimum area of a triangulation of p[i..j],
+	// split[i][j] the corresponding third vertex of the triangle on edge i-j.
+	weight := make([][]float64, n)
+	split := make([][]int, n)
+	for i := range weight {
+		weight[i] = make([]float64, n)
+		split[i] = make([]int, n)
+	}
+	for length := 2; length < n; length++ {
+		for i := 0; i+length < n; i++ {
+			j := i + length
+			weight[i][j] = math.Inf(1)
+			for k := i + 1; k < j; k++ {
+				area := 0.5 * p[k].Diff(p[i]).Cross(p[j].Diff(p[i])).Len()
+				w := weight[i][k] + weight[k][j] + area
+				if w < weight[i][j] {
+					weight[i][j] = w
+					split[i][j] = k
+				}
+			}
+		}
+	}
+
+	faces := make([][3]int, 0, n-2)
+	var collect func(i, j int)
+	collect = func(i, j int) {
+		if j-i < 2 {
+			return
+		}
+		k := split[i][j]
+		faces = append(faces, [3]int{i, k, j})
+		collect(i, k)
+		collect(k, j)
+	}
+	collect(0, n-1)
+	return faces
+}
+
+// smoothPatch refines the patch of faces filling a hole, whose first
+// boundaryCount vertices form the boundary, and moves the new vertices
+// to a smooth membrane spanned by the boundary.
+func smoothPatch(vertices []Vec3, boundaryCount int, faces [][3]int) ([]Vec3, [][3]int) {
+	vertices = append([]Vec3(nil), vertices...)
+	isBoundaryEdge := func(a, b int) bool {
+		return a < boundaryCount && b < boundaryCount &&
+			((a+1)%boundaryCount == b || (b+1)%boundaryCount == a)
+	}
+
+	for r := 0; r < smoothFillRefinements; r++ {
+		// split every inner edge at its midpoint
+		midpoints := make(map[[2]int]int)
+		midpoint := func(a, b int) int {
+			if isBoundaryEdge(a, b) {
+				return -1
+			}
+			key := [2]int{a, b}
+			if a > b {
+				key = [2]int{b, a}
+			}
+			if m, found := midpoints[key]; found {
+				return m
+			}
+			m := len(vertices)
+			vertices = append(vertices, vertices[a].Add(vertices[b]).MultScalar(0.5))
+			midpoints[key] = m
+			return m
+		}
+		refined := make([][3]int, 0, 4*len(faces))
+		for _, f := range faces {
+			var mids [3]int
+			split := 0
+			for e := 0; e < 3; e++ {
+				mids[e] = midpoint(f[e], f[(e+1)%3])
+				if mids[e] >= 0 {
+					split++
+				}
+			}
+			refined = append(refined, splitTriangle(f, mids, split)...)
+		}
+		faces = refined
+	}
+
+	// relax the inner vertices towards the average of their neighbors
+	neighbors := make([][]int, len(vertices))
+	for _, f := range faces {
+		for e := 0; e < 3; e++ {
+			a, b := f[e], f[(e+1)%3]
+			neighbors[a] = append(neighbors[a], b)
+			neighbors[b] = append(neighbors[b], a)
+		}
+	}
+	for it := 0; it < smoothFillIterations; it++ {
+		for v := boundaryCount; v < len(vertices); v++ {
+			var sum Vec3
+			for _, w := range neighbors[v] {
+				sum = sum.Add(vertices[w])
+			}
+			vertices[v] = sum.MultScalar(1 / float64(len(neighbors[v])))
+		}
+	}
+	return vertices, faces
+}
+
+// splitTriangle splits face f, with mids containing the vertex index of the
+// midpoint of each edge, or -1 if that edge is not split. split is the number
+// of edges split. The orientation of f is kept.
+func splitTriangle(f [3]int, mids [3]int, split int) [][3]int {
+	switch split {
+	case 3:
+		return [][3]int{
+			{f[0], mids[0], mids[2]},
+			{f[1], mids[1], mids[0]},
+			{f[2], mids[2], mids[1]},
+			{mids[0], mids[1], mids[2]},
+		}
+	case 2:
+		// rotate so that edge 0 is the one not split
+		for mids[0] >= 0 {
+			f = [3]int{f[1], f[2], f[0]}
+			mids = [3]int{mids[1], mids[2], mids[0]}
+		}
+		return [][3]int{
+			{f[2], mids[2], mids[1]},
+			{f[0], f[1], mids[1]},
+			{f[0], mids[1], mids[2]},
+		}
+	case 1:
+		// rotate so that edge 0 is the one split
+		for mids[0] < 0 {
+			f = [3]int{f[1], f[2], f[0]}
+			mids = [3]int{mids[1], mids[2], mids[0]}
+		}
+		return [][3]int{
+			{f[0], mids[0], f[2]},
+			{mids[0], f[1], f[2]},
+		}
+	}
+	return [][3]int{f}
+}
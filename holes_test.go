@@ -0,0 +1,126 @@
+package stl
+
+// Tests for the detection and filling of holes.
+
+import (
+	"testing"
+)
+
+func TestFindHoles(t *testing.T) {
+	s := makeTestSolid()
+	missing := s.Triangles[0]
+	s.Triangles = s.Triangles[1:]
+	holes := s.FindHoles()
+	if len(holes) != 1 || len(holes[0].Vertices) != 3 {
+		t.Fatalf("Expected one hole with 3 vertices, found %v", holes)
+	}
+	// The hole has the orientation of the missing triangle
+	found := false
+	for r := 0; r < 3; r++ {
+		if holes[0].Vertices[r] == missing.Vertices[0] &&
+			holes[0].Vertices[(r+1)%3] == missing.Vertices[1] &&
+			holes[0].Vertices[(r+2)%3] == missing.Vertices[2] {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected hole vertices %v, found %v", missing.Vertices, holes[0].Vertices)
+	}
+	for i, ti := range holes[0].Triangles {
+		v, w := holes[0].Vertices[i], holes[0].Vertices[(i+1)%3]
+		tr := s.Triangles[ti]
+		hasEdge := false
+		for e := 0; e < 3; e++ {
+			if tr.Vertices[e] == w && tr.Vertices[(e+1)%3] == v {
+				hasEdge = true
+			}
+		}
+		if !hasEdge {
+			t.Errorf("Triangle %d does not border hole edge %d", ti, i)
+		}
+	}
+
+	if holes := makeTestSolid().FindHoles(); len(holes) != 0 {
+		t.Errorf("Expected no holes, found %v", holes)
+	}
+}
+
+// makeTorusWithHole returns a welded torus with a hole of size*size quads
+func makeTorusWithHole(size int) *Solid {
+	s := makeTorusTestSolid(2, 0.5, 16, 12)
+	s.WeldVertices(0.000001)
+	var kept []Triangle
+	for i, tr := range s.Triangles {
+		quad := i / 2
+		if quad/12 >= size || quad%12 >= size {
+			kept = append(kept, tr)
+		}
+	}
+	s.Triangles = kept
+	return s
+}
+
+func TestFillHoles(t *testing.T) {
+	for _, opts := range []FillHolesOptions{
+		{Method: FillFan},
+		{Method: FillMinimumArea},
+		{Method: FillMinimumArea, Smooth: true},
+	} {
+		s := makeTorusWithHole(3)
+		holes := s.FindHoles()
+		if len(holes) != 1 || len(holes[0].Vertices) != 12 {
+			t.Fatalf("Expected one hole with 12 vertices, found %v", holes)
+		}
+		if filled := s.FillHoles(opts); filled != 1 {
+			t.Errorf("%+v: expected 1 hole to be filled, found %d", opts, filled)
+		}
+		report := s.Topology(0)
+		if !report.IsClosed || !report.IsConsistentlyOriented || !report.IsEdgeManifold {
+			t.Errorf("%+v: expected closed and oriented solid, found %+v", opts, report)
+		}
+		if len(report.Shells) != 1 || report.Shells[0].Genus != 1 {
+			t.Errorf("%+v: expected one shell of genus 1, found %+v", opts, report.Shells)
+		}
+	}
+}
+
+func TestFillHoles_MaxSize(t *testing.T) {
+	s := makeTorusWithHole(3)
+	if filled := s.FillHoles(FillHolesOptions{MaxEdges: 11}); filled != 0 {
+		t.Errorf("Expected hole to be left alone, found %d filled", filled)
+	}
+	perimeter := s.FindHoles()[0].Perimeter()
+	if filled := s.FillHoles(FillHolesOptions{MaxPerimeter: perimeter * 0.99}); filled != 0 {
+		t.Errorf("Expected hole to be left alone, found %d filled", filled)
+	}
+	if filled := s.FillHoles(FillHolesOptions{MaxEdges: 12, MaxPerimeter: perimeter * 1.01}); filled != 1 {
+		t.Errorf("Expected hole to be filled, found %d filled", filled)
+	}
+}
+
+func TestIndexedMesh_FillHoles(t *testing.T) {
+	for _, opts := range []FillHolesOptions{
+		{Method: FillMinimumArea},
+		{Method: FillMinimumArea, Smooth: true},
+	} {
+		m := makeTorusWithHole(3).ToIndexed(0)
+		vertexCount := len(m.Vertices)
+		holes := m.FindHoles()
+		if len(holes) != 1 || len(holes[0].Vertices) != 12 {
+			t.Fatalf("Expected one hole with 12 vertices, found %v", holes)
+		}
+		if filled := m.FillHoles(opts); filled != 1 {
+			t.Errorf("%+v: expected 1 hole to be filled, found %d", opts, filled)
+		}
+		if added := len(m.Vertices) - vertexCount; (added > 0) != opts.Smooth {
+			t.Errorf("%+v: expected new vertices only for smooth fill, found %d", opts, added)
+		}
+		if len(m.Normals) != len(m.Faces) || len(m.Attributes) != len(m.Faces) {
+			t.Errorf("%+v: expected normals and attributes for all faces", opts)
+		}
+		report := m.Topology()
+		if !report.IsClosed || !report.IsConsistentlyOriented || !report.IsEdgeManifold {
+			t.Errorf("%+v: expected closed and oriented mesh, found %+v", opts, report)
+		}
+	}
+}
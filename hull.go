@@ -0,0 +1,302 @@
+package stl
+
+// This file contains the calculation of convex hulls using Quickhull.
+
+import (
+	"math"
+)
+
+// hullFace is a triangle of the convex hull under construction.
+type hullFace struct {
+	v      [3]int
+	normal Vec3    // unit normal pointing outside
+	offset float64 // normal.Dot(p) for points p in the plane
+
+	// outside are the points in front of this face, not yet on the hull
+	outside []int
+
+	deleted bool
+}
+
+func (f *hullFace) distance(p Vec3) float64 {
+	return f.normal.Dot(p) - f.offset
+}
+
+// quickhull contains the state of the Quickhull algorithm.
+type quickhull struct {
+	points []Vec3
+	tol    float64
+	faces  []hullFace
+	edges  map[[2]int]int // directed edge to the index of the face containing it
+}
+
+// convexHull calculates the convex hull of points using Quickhull. The faces
+// contain indices into points, and are oriented counter-clockwise when looking
+// from outside. Points closer than a tolerance scaled to the coordinates to
+// a face are considered to lie on it, so duplicate and coplanar points are
+// handled. If the points do not span a volume, ok is false.
+func convexHull(points []Vec3) (faces [][3]int, ok bool) {
+	if len(points) < 4 {
+		return nil, false
+	}
+	h := quickhull{points: points, edges: make(map[[2]int]int)}
+	var maxCoord Vec3
+	for _, p := range points {
+		for d := 0; d < 3; d++ {
+			maxCoord[d] = math.Max(maxCoord[d], math.Abs(p[d]))
+		}
+	}
+	h.tol = 3 * epsilon64 * (maxCoord[0] + maxCoord[1] + maxCoord[2])
+
+	simplex, ok := h.initialSimplex()
+	if !ok {
+		return nil, false
+	}
+	h.addFace(simplex[0], simplex[1], simplex[2])
+	h.addFace(simplex[0], simplex[2], simplex[3])
+	h.addFace(simplex[0], simplex[3], simplex[1])
+	h.addFace(simplex[1], simplex[3], simplex[2])
+	if h.faces[0].distance(points[simplex[3]]) > 0 {
+		// the simplex was oriented inside out
+		for i := range h.faces {
+			f := &h.faces[i]
+			f.v[1], f.v[2] = f.v[2], f.v[1]
+			f.normal = f.normal.MultScalar(-1)
+			f.offset = -f.offset
+		}
+		h.edges = make(map[[2]int]int)
+		for i := range h.faces {
+			h.registerEdges(i)
+		}
+	}
+
+	all := make([]int, 0, len(points))
+	for i := range points {
+		if i != simplex[0] && i != simplex[1] && i != simplex[2] && i != simplex[3] {
+			all = append(all, i)
+		}
+	}
+	h.assignOutside(all, []int{0, 1, 2, 3})
+
+	for i := 0; i < len(h.faces); i++ {
+		for !h.faces[i].deleted && len(h.faces[i].outside) > 0 {
+			h.addPoint(i)
+		}
+	}
+
+	for _, f := range h.faces {
+		if !f.deleted {
+			faces = append(faces, f.v)
+		}
+	}
+	return faces, true
+}
+
+// epsilon64 is the machine epsilon of float64.
+const epsilon64 = 2.220446049250313e-16
+
+// initialSimplex chooses four points spanning a tetrahedron of maximal size.
+func (h *quickhull) initialSimplex() (simplex [4]int, ok bool) {
+	// the two most distant of the extreme points along the axes
+	var extremes [6]int
+	for i, p := range h.points {
+		for d := 0; d < 3; d++ {
+			if p[d] < h.points[extremes[2*d]][d] {
+				extremes[2*d] = i
+			}
+			if p[d] > h.points[extremes[2*d+1]][d] {
+				extremes[2*d+1] = i
+			}
+		}
+	}
+	best := -1.0
+	for _, a := range extremes {
+		for _, b := range extremes {
+			if dist := h.points[a].Diff(h.points[b]).Len(); dist > best {
+				best = dist
+				simplex[0], simplex[1] = a, b
+			}
+		}
+	}
+	if best <= h.tol {
+		return simplex, false
+	}
+
+	// the point most distant from the line
+	p0, p1 := h.points[simplex[0]], h.points[simplex[1]]
+	dir := p1.Diff(p0).UnitVec3()
+	best = -1
+	for i, p := range h.points {
+		if dist := p.Diff(p0).Cross(dir).Len(); dist > best {
+			best = dist
+			simplex[2] = i
+		}
+	}
+	if best <= h.tol {
+		return simplex, false
+	}
+
+	// the point most distant from the plane
+	normal := p1.Diff(p0).Cross(h.points[simplex[2]].Diff(p0)).UnitVec3()
+	best = -1
+	for i, p := range h.points {
+		if dist := math.Abs(p.Diff(p0).Dot(normal)); dist > best {
+			best = dist
+			simplex[3] = i
+		}
+	}
+	return simplex, best > h.tol
+}
+
+// addFace adds the face a, b, c and returns its index.
+func (h *quickhull) addFace(a, b, c int) int {
+	pa, pb, pc := h.points[a], h.points[b], h.points[c]
+	normal := pb.Diff(pa).Cross(pc.Diff(pa)).UnitVec3()
+	h.faces = append(h.faces, hullFace{
+		v:      [3]int{a, b, c},
+		normal: normal,
+		offset: normal.Dot(pa),
+	})
+	i := len(h.faces) - 1
+	h.registerEdges(i)
+	return i
+}
+
+func (h *quickhull) registerEdges(i int) {
+	v := h.faces[i].v
+	for e := 0; e < 3; e++ {
+		h.edges[[2]int{v[e], v[(e+1)%3]}] = i
+	}
+}
+
+// assignOutside assigns each of points to the first of faces it is in
+// front of. Points not in front of any face are dropped.
+func (h *quickhull) assignOutside(points []int, faces []int) {
+	for _, p := range points {
+		for _, f := range faces {
+			if h.faces[f].distance(h.points[p]) > h.tol {
+				h.faces[f].outside = append(h.faces[f].outside, p)
+				break
+			}
+		}
+	}
+}
+
+// addPoint adds the most distant outside point of face i to the hull.
+func (h *quickhull) addPoint(i int) {
+	face := &h.faces[i]
+	eye, best := -1, -1.0
+	for _, p := range face.outside {
+		if dist := face.distance(h.points[p]); dist > best {
+			best = dist
+			eye = p
+		}
+	}
+	eyePoint := h.points[eye]
+
+	// find the faces visible from the eye point, and the horizon
+	visible := []int{i}
+	h.faces[i].deleted = true
+	var horizon [][2]int
+	for j := 0; j < len(visible); j++ {
+		v := h.faces[visible[j]].v
+		for e := 0; e < 3; e++ {
+			a, b := v[e], v[(e+1)%3]
+			other := h.edges[[2]int{b, a}]
+			if h.faces[other].deleted {
+				continue
+			}
+			if h.faces[other].distance(eyePoint) > h.tol {
+				h.faces[other].deleted = true
+				visible = append(visible, other)
+			} else {
+				horizon = append(horizon, [2]int{a, b})
+			}
+		}
+	}
+
+	// connect the horizon to the eye point, and reassign the outside points
+	// of the removed faces to the new ones
+	var orphans []int
+	for _, f := range visible {
+		for _, p := range h.faces[f].outside {
+			if p != eye {
+				orphans = append(orphans, p)
+			}
+		}
+		h.faces[f].outside = nil
+	}
+	var newFaces []int
+	for _, e := range horizon {
+		newFaces = append(newFaces, h.addFace(e[0], e[1], eye))
+	}
+	h.assignOutside(orphans, newFaces)
+}
+
+// ConvexHull returns the convex hull of the solid's vertices as a closed,
+// consistently oriented solid, see ConvexHullOfPoints. The name and format
+// of the solid are kept.
+func (s *Solid) ConvexHull() *Solid {
+	hull := ConvexHullOfPoints(s.uniqueVertices())
+	hull.Name = s.Name
+	hull.IsAscii = s.IsAscii
+	return hull
+}
+
+// ConvexHullOfPoints returns the convex hull of points as a closed solid,
+// with the normals pointing outside. Duplicate points, and points on the
+// faces of the hull are left out. If the points lie in a plane, the result
+// is the flat polygon around them, triangulated on both sides. If they lie
+// on a line, the result has no triangles.
+func ConvexHullOfPoints(points []Vec3) *Solid {
+	var hull Solid
+	addTriangle := func(a, b, c Vec3) {
+		t := Triangle{Vertices: [3]Vec3{a, b, c}}
+		t.recalculateNormal()
+		hull.AppendTriangle(t)
+	}
+
+	if faces, ok := convexHull(points); ok {
+		for _, f := range faces {
+			addTriangle(points[f[0]], points[f[1]], points[f[2]])
+		}
+		return &hull
+	}
+
+	// flat or degenerate: find the polygon in the plane with the smallest spread
+	axes := principalAxes(points)
+	u, v := axes[2], axes[1]
+	projected := make([][2]float64, len(points))
+	byProjection := make(map[[2]float64]Vec3, len(points))
+	for i, p := range points {
+		projected[i] = [2]float64{u.Dot(p), v.Dot(p)}
+		byProjection[projected[i]] = p
+	}
+	outline := convexHull2D(projected)
+	if len(outline) < 3 || polygonArea2D(outline) <= 0 {
+		return &hull
+	}
+	polygon := make([]Vec3, len(outline))
+	for i, p := range outline {
+		polygon[i] = byProjection[p]
+	}
+	// fans from different vertices on both sides, so no edge is used more
+	// than twice
+	n := len(polygon)
+	for i := 1; i < n-1; i++ {
+		addTriangle(polygon[0], polygon[i], polygon[i+1])
+		addTriangle(polygon[1], polygon[(i+2)%n], polygon[i+1])
+	}
+	return &hull
+}
+
+// polygonArea2D returns the signed area of polygon, positive if it is
+// counter-clockwise.
+func polygonArea2D(polygon [][2]float64) float64 {
+	var area float64
+	for i, p := range polygon {
+		q := polygon[(i+1)%len(polygon)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return area / 2
+}
@@ -0,0 +1,99 @@
+package stl
+
+// Tests for the calculation of convex hulls.
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkHull verifies that hull is a closed, oriented and convex solid
+// containing points.
+func checkHull(t *testing.T, hull *Solid, points []Vec3) {
+	t.Helper()
+	report := hull.Topology(0)
+	if !report.IsClosed || !report.IsEdgeManifold || !report.IsConsistentlyOriented {
+		t.Errorf("Expected closed and oriented hull, found %+v", report)
+	}
+	if errors := hull.Validate(); len(errors) != 0 {
+		t.Errorf("Expected valid hull, found %d errors", len(errors))
+	}
+	for _, tri := range hull.Triangles {
+		for _, p := range points {
+			if d := p.Diff(tri.Vertices[0]).Dot(tri.Normal); d > 1e-9 {
+				t.Fatalf("Point %v is %v in front of hull triangle %v", p, d, tri)
+			}
+		}
+	}
+}
+
+func TestConvexHullOfPoints_Grid(t *testing.T) {
+	// a 3x3x3 grid has coplanar points on the faces and an inner point
+	var points []Vec3
+	for x := -1.0; x <= 1; x++ {
+		for y := -1.0; y <= 1; y++ {
+			for z := -1.0; z <= 1; z++ {
+				points = append(points, Vec3{x, y, z}, Vec3{x, y, z})
+			}
+		}
+	}
+	hull := ConvexHullOfPoints(points)
+	checkHull(t, hull, points)
+	if volume := hull.MassProperties(1).Volume; !almostEqual64(volume, 8, 1e-12) {
+		t.Errorf("Expected volume 8, found %v", volume)
+	}
+	for _, tri := range hull.Triangles {
+		for _, v := range tri.Vertices {
+			if math.Abs(v[0]) != 1 || math.Abs(v[1]) != 1 || math.Abs(v[2]) != 1 {
+				t.Errorf("Expected only corners on the hull, found %v", v)
+			}
+		}
+	}
+}
+
+func TestConvexHullOfPoints_Sphere(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	points := make([]Vec3, 2000)
+	for i := range points {
+		points[i] = Vec3{r.NormFloat64(), r.NormFloat64(), r.NormFloat64()}.UnitVec3()
+	}
+	hull := ConvexHullOfPoints(points)
+	checkHull(t, hull, points)
+	// all points are on the hull
+	if v := len(hull.ToIndexed(0).Vertices); v != len(points) {
+		t.Errorf("Expected %d hull vertices, found %d", len(points), v)
+	}
+}
+
+func TestConvexHullOfPoints_Flat(t *testing.T) {
+	points := []Vec3{{0, 0, 0}, {2, 0, 0}, {2, 2, 0}, {0, 2, 0}, {1, 1, 0}, {1, 0, 0}, {2, 2, 0}}
+	hull := ConvexHullOfPoints(points)
+	checkHull(t, hull, points)
+	if len(hull.Triangles) != 4 {
+		t.Errorf("Expected square triangulated on both sides, found %d triangles", len(hull.Triangles))
+	}
+
+	if hull := ConvexHullOfPoints([]Vec3{{0, 0, 0}, {1, 1, 1}, {2, 2, 2}}); len(hull.Triangles) != 0 {
+		t.Errorf("Expected no triangles for points on a line, found %v", hull.Triangles)
+	}
+	if hull := ConvexHullOfPoints(nil); len(hull.Triangles) != 0 {
+		t.Errorf("Expected no triangles for no points, found %v", hull.Triangles)
+	}
+}
+
+func TestConvexHull(t *testing.T) {
+	s := makeTorusTestSolid(2, 0.5, 24, 12)
+	hull := s.ConvexHull()
+	var points []Vec3
+	for _, tri := range s.Triangles {
+		points = append(points, tri.Vertices[:]...)
+	}
+	checkHull(t, hull, points)
+	if hull.Name != s.Name {
+		t.Errorf("Expected name %q, found %q", s.Name, hull.Name)
+	}
+	if volume, torusVolume := hull.MassProperties(1).Volume, s.MassProperties(1).Volume; volume <= torusVolume {
+		t.Errorf("Expected hull volume larger than %v, found %v", torusVolume, volume)
+	}
+}
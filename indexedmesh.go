@@ -0,0 +1,316 @@
+package stl
+
+// This file provides the IndexedMesh data type, a representation
+// of a solid with shared vertices.
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// IndexedMesh represents a solid by a list of vertices and faces referring
+// to them by index. As opposed to Solid, topology questions can be answered
+// by comparing vertex indices instead of coordinates.
+type IndexedMesh struct {
+	// Name is the solid's name
+	Name string
+
+	// only used in binary format
+	BinaryHeader []byte
+
+	// IsAscii is used to determine the format when writing to a file,
+	// see Solid.IsAscii.
+	IsAscii bool
+
+	// Vertices are the distinct vertices of the mesh.
+	Vertices []Vec3
+
+	// Faces contains one triangle per element, given by indices in Vertices.
+	// The vertex order is the same as in Triangle.Vertices.
+	Faces [][3]uint32
+
+	// Normals contains the normal vector of each face, indexed like Faces.
+	// If it does not have the same length as Faces, normals are calculated
+	// from the vertices.
+	Normals []Vec3
+
+	// Attributes contains the attributes of each face, indexed like Faces,
+	// see Triangle.Attributes. It can be empty.
+	Attributes []uint16
+}
+
+// ToIndexed converts the solid into an IndexedMesh. Vertices closer to
+// each other than weldTolerance are merged into one, the position of
+// the first one found is used. If weldTolerance is 0, only exactly equal
+// vertices are merged.
+func (s *Solid) ToIndexed(weldTolerance float64) *IndexedMesh {
+	m := IndexedMesh{
+		Name:         s.Name,
+		BinaryHeader: s.BinaryHeader,
+		IsAscii:      s.IsAscii,
+		Faces:        make([][3]uint32, len(s.Triangles)),
+		Normals:      make([]Vec3, len(s.Triangles)),
+		Attributes:   make([]uint16, len(s.Triangles)),
+	}
+	w := newVertexWelder(weldTolerance, len(s.Triangles)/2)
+	for i := range s.Triangles {
+		t := &s.Triangles[i]
+		for v := 0; v < 3; v++ {
+			m.Faces[i][v] = w.index(t.Vertices[v])
+		}
+		m.Normals[i] = t.Normal
+		m.Attributes[i] = t.Attributes
+	}
+	m.Vertices = w.vertices
+	return &m
+}
+
+// Triangle returns the face with index i as a Triangle.
+func (m *IndexedMesh) Triangle(i int) Triangle {
+	f := m.Faces[i]
+	t := Triangle{
+		Vertices: [3]Vec3{m.Vertices[f[0]], m.Vertices[f[1]], m.Vertices[f[2]]},
+	}
+	if len(m.Normals) == len(m.Faces) {
+		t.Normal = m.Normals[i]
+	} else {
+		t.recalculateNormal()
+	}
+	if len(m.Attributes) == len(m.Faces) {
+		t.Attributes = m.Attributes[i]
+	}
+	return t
+}
+
+// ToSolid converts the mesh back into a Solid.
+func (m *IndexedMesh) ToSolid() *Solid {
+	s := Solid{
+		Name:         m.Name,
+		BinaryHeader: m.BinaryHeader,
+		IsAscii:      m.IsAscii,
+		Triangles:    make([]Triangle, len(m.Faces)),
+	}
+	for i := range m.Faces {
+		s.Triangles[i] = m.Triangle(i)
+	}
+	return &s
+}
+
+// RecalculateNormals sets m.Normals to the normals calculated from the
+// vertices of the faces.
+func (m *IndexedMesh) RecalculateNormals() {
+	if len(m.Normals) != len(m.Faces) {
+		m.Normals = make([]Vec3, len(m.Faces))
+	}
+	for i := range m.Faces {
+		t := Triangle{Vertices: [3]Vec3{m.Vertices[m.Faces[i][0]], m.Vertices[m.Faces[i][1]], m.Vertices[m.Faces[i][2]]}}
+		m.Normals[i] = t.CalculatedNormal()
+	}
+}
+
+// WriteFile creates file with name filename and writes the mesh into it,
+// see Solid.WriteFile.
+func (m *IndexedMesh) WriteFile(filename string) error {
+	return m.ToSolid().WriteFile(filename)
+}
+
+// WriteAll writes the mesh to an io.Writer, see Solid.WriteAll.
+func (m *IndexedMesh) WriteAll(w io.Writer) error {
+	return m.ToSolid().WriteAll(w)
+}
+
+// hasEqualVertices returns true if face i refers to the same vertex more than once.
+func (m *IndexedMesh) hasEqualVertices(i int) bool {
+	f := m.Faces[i]
+	return f[0] == f[1] || f[0] == f[2] || f[1] == f[2]
+}
+
+// faceKey identifies a face independently of the rotation of its vertices.
+// The key contains the vertex indices in ascending order. forward is
+// true if the face's orientation matches that order.
+func (m *IndexedMesh) faceKey(i int) (key [3]uint32, forward bool) {
+	f := m.Faces[i]
+	// rotate the smallest index to the front
+	for f[0] > f[1] || f[0] > f[2] {
+		f = [3]uint32{f[1], f[2], f[0]}
+	}
+	if f[1] <= f[2] {
+		return f, true
+	}
+	return [3]uint32{f[0], f[2], f[1]}, false
+}
+
+// duplicateFaces groups the indices of faces with the same vertices.
+// Only groups with more than one face are returned, ordered by their
+// first face.
+func (m *IndexedMesh) duplicateFaces() [][]int {
+	faces := m.newFaceTable()
+	var groups [][]int
+	for v := range m.Vertices {
+		bucket := faces.entries[faces.offsets[v]:faces.offsets[v+1]]
+		for start := 0; start < len(bucket); {
+			end := start + 1
+			for end < len(bucket) && bucket[end].key == bucket[start].key {
+				end++
+			}
+			if end-start > 1 {
+				group := make([]int, end-start)
+				for j := range group {
+					group[j] = int(bucket[start+j].face)
+				}
+				groups = append(groups, group)
+			}
+			start = end
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+	return groups
+}
+
+// Validate looks for errors like Solid.Validate, but compares vertex indices
+// instead of coordinates.
+func (m *IndexedMesh) Validate() map[int]*TriangleErrors {
+	return m.validate(0, 1)
+}
+
+// validate implements Validate, with tol being the tolerance used to
+// detect degenerate faces. The faces are checked by the given number
+// of goroutines.
+func (m *IndexedMesh) validate(tol float64, workers int) map[int]*TriangleErrors {
+	edges := m.newEdgeTable()
+
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]triangleErrorsMap, workers)
+	var wg sync.WaitGroup
+	chunk := (len(m.Faces) + workers - 1) / workers
+	for w := range results {
+		results[w] = make(triangleErrorsMap)
+		start, end := w*chunk, (w+1)*chunk
+		if end > len(m.Faces) {
+			end = len(m.Faces)
+		}
+		wg.Add(1)
+		go func(triangleErrors triangleErrorsMap, start, end int) {
+			defer wg.Done()
+			m.validateFaces(tol, edges, triangleErrors, start, end)
+		}(results[w], start, end)
+	}
+	wg.Wait()
+	triangleErrors := results[0]
+	for _, r := range results[1:] {
+		for i, te := range r {
+			triangleErrors[i] = te
+		}
+	}
+
+	for _, group := range m.duplicateFaces() {
+		for _, i := range group {
+			_, forward := m.faceKey(i)
+			for _, j := range group {
+				if j == i {
+					continue
+				}
+				te := triangleErrors.item(i)
+				if _, otherForward := m.faceKey(j); otherForward == forward {
+					te.DuplicateTriangles = append(te.DuplicateTriangles, j)
+				} else {
+					te.FlippedDuplicateTriangles = append(te.FlippedDuplicateTriangles, j)
+				}
+			}
+		}
+	}
+
+	corners := m.fanCorners(edges)
+	for _, fans := range m.vertexFans(corners, m.nonManifoldVertices(corners)) {
+		for i, fan := range fans {
+			var others []int
+			for j, otherFan := range fans {
+				if j != i {
+					others = appendFaces(others, otherFan)
+				}
+			}
+			for _, c := range fan {
+				triangleErrors.item(c / 3).vertex(c % 3).OtherFanTriangles = others
+			}
+		}
+	}
+
+	return triangleErrors
+}
+
+// validateFaces checks the faces start..end-1 for errors that only concern
+// a single face and its edges.
+func (m *IndexedMesh) validateFaces(tol float64, edges *bucketTable, triangleErrors triangleErrorsMap, start, end int) {
+	checkNormals := len(m.Normals) == len(m.Faces)
+	var buf []int
+	for i := start; i < end; i++ {
+		f := m.Faces[i]
+		if m.hasEqualVertices(i) {
+			triangleErrors.item(i).HasEqualVertices = true
+		}
+
+		t := m.Triangle(i)
+		if t.isCollinear(tol) {
+			triangleErrors.item(i).IsDegenerate = true
+		}
+
+		if checkNormals && !t.checkNormal(normalAngleTolerance) {
+			triangleErrors.item(i).NormalDoesNotMatch = true
+		}
+
+		for vertex1 := 0; vertex1 < 3; vertex1++ {
+			vertex2 := (vertex1 + 1) % 3
+
+			// the faces are collected in buf, and only copied if they are reported
+			buf = edges.appendFacesWithEdge(buf[:0], f[vertex1], f[vertex2], i)
+			if len(buf) > 0 {
+				triangleErrors.item(i).edge(vertex1).SameEdgeTriangles = append([]int(nil), buf...)
+			}
+
+			buf = edges.appendFacesWithEdge(buf[:0], f[vertex2], f[vertex1], i)
+			if len(buf) != 1 {
+				triangleErrors.item(i).edge(vertex1).CounterEdgeTriangles = append([]int(nil), buf...)
+			}
+		}
+	}
+}
+
+// fanCorners returns the same corner sets as edgeUses, using the edge table
+// instead of a map.
+func (m *IndexedMesh) fanCorners(edges *bucketTable) disjointSet {
+	corners := newDisjointSet(3 * len(m.Faces))
+	for i, f := range m.Faces {
+		for e := 0; e < 3; e++ {
+			v, w := f[e], f[(e+1)%3]
+			if v == w {
+				continue
+			}
+			first := edges.firstFaceWithEdge(v, w)
+			if first == i && !m.hasEarlierEdge(i, e) {
+				// the first use of the edge
+				continue
+			}
+			corners.union(3*i+e, m.cornerOf(first, v))
+			corners.union(3*i+(e+1)%3, m.cornerOf(first, w))
+		}
+	}
+	return corners
+}
+
+// hasEarlierEdge is true if face i contains the undirected edge e before
+// edge e, which is only possible for degenerate faces.
+func (m *IndexedMesh) hasEarlierEdge(i, e int) bool {
+	f := m.Faces[i]
+	key := undirectedEdge(f[e], f[(e+1)%3])
+	for earlier := 0; earlier < e; earlier++ {
+		if undirectedEdge(f[earlier], f[(earlier+1)%3]) == key {
+			return true
+		}
+	}
+	return false
+}
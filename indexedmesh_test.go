@@ -0,0 +1,59 @@
+package stl
+
+// Tests for the IndexedMesh data type.
+
+import (
+	"testing"
+)
+
+func TestToIndexed(t *testing.T) {
+	s := makeTestSolid()
+	m := s.ToIndexed(0)
+	if len(m.Vertices) != 4 {
+		t.Errorf("Expected 4 vertices, found %d", len(m.Vertices))
+	}
+	if len(m.Faces) != 4 {
+		t.Errorf("Expected 4 faces, found %d", len(m.Faces))
+	}
+	if !s.sameOrderAlmostEqual(m.ToSolid()) {
+		t.Error("Not equal after conversion to IndexedMesh and back")
+		t.Log("Expected:\n", s)
+		t.Log("Found:\n", m.ToSolid())
+	}
+}
+
+func TestToIndexed_WeldTolerance(t *testing.T) {
+	s := makeTestSolid()
+	s.Triangles[2].Vertices[0] = Vec3{0, 0, 1.0000001}
+	if n := len(s.ToIndexed(0).Vertices); n != 5 {
+		t.Errorf("Expected 5 vertices without tolerance, found %d", n)
+	}
+	m := s.ToIndexed(0.00001)
+	if len(m.Vertices) != 4 {
+		t.Errorf("Expected 4 vertices with tolerance, found %d", len(m.Vertices))
+	}
+	if errors := m.Validate(); len(errors) != 0 {
+		t.Errorf("Expected no errors after welding, found %v", errors)
+	}
+}
+
+func TestIndexedMeshValidate(t *testing.T) {
+	solid := makeBrokenTestSolid()
+	errors := solid.ToIndexed(0).Validate()
+	if errors[0] == nil || !errors[0].HasEqualVertices {
+		t.Error("Failed to detect HasEqualVertices in triangle 0")
+	}
+	if errors[1] == nil || errors[1].EdgeErrors[0] == nil ||
+		!errors[1].EdgeErrors[0].HasNoCounterEdge() {
+		t.Error("Failed to detect missing counter-edge in triangle 1, edge 0")
+	}
+	if errors[2] == nil || errors[2].EdgeErrors[1] == nil ||
+		!errors[2].EdgeErrors[1].HasNoCounterEdge() {
+		t.Error("Failed to detect missing counter-edge in triangle 2, edge 1")
+	}
+	if errors[3] == nil || errors[3].EdgeErrors[2] == nil ||
+		!errors[3].EdgeErrors[2].IsUsedInOtherTriangles() ||
+		errors[3].EdgeErrors[2].SameEdgeTriangles[0] != 0 {
+		t.Error("Failed to detect edge duplicate of triangle 3, edge 2 in triangle 0")
+	}
+}
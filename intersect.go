@@ -0,0 +1,297 @@
+package stl
+
+// This file contains the detection of self-intersections.
+
+import (
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// SelfIntersection describes two triangles of a solid intersecting each other.
+type SelfIntersection struct {
+	// Triangles are the indices in Solid.Triangles of the intersecting
+	// triangles, the lower one first.
+	Triangles [2]int
+
+	// Segment is the line segment where the triangles intersect. It is
+	// not set for coplanar triangles.
+	Segment [2]Vec3
+
+	// Coplanar is true if the triangles lie in the same plane and overlap.
+	Coplanar bool
+}
+
+// relativeIntersectionTolerance is multiplied with the size of two triangles
+// to get the tolerance used for intersection tests between them.
+const relativeIntersectionTolerance = 1e-9
+
+// planarTriangle contains precalculated data of a triangle for
+// intersection tests.
+type planarTriangle struct {
+	t *Triangle
+
+	// normal is the unit normal calculated from the vertices, or
+	// Vec3Zero for degenerate triangles.
+	normal Vec3
+
+	// edgeNormals are unit vectors in the triangle's plane orthogonal
+	// to the edges, pointing inside.
+	edgeNormals [3]Vec3
+
+	// size is the length of the bounding box diagonal.
+	size float64
+}
+
+func newPlanarTriangle(t *Triangle) planarTriangle {
+	pt := planarTriangle{t: t}
+	pt.normal = t.Vertices[1].Diff(t.Vertices[0]).Cross(t.Vertices[2].Diff(t.Vertices[0])).UnitVec3()
+	for e := 0; e < 3; e++ {
+		edge := t.Vertices[(e+1)%3].Diff(t.Vertices[e])
+		pt.edgeNormals[e] = pt.normal.Cross(edge).UnitVec3()
+	}
+	box := triangleAABB(t)
+	pt.size = box.max.Diff(box.min).Len()
+	return pt
+}
+
+// distance returns the signed distance of p from the triangle's plane.
+func (pt *planarTriangle) distance(p Vec3) float64 {
+	return p.Diff(pt.t.Vertices[0]).Dot(pt.normal)
+}
+
+// contains is true if x, lying in the triangle's plane, is inside the
+// triangle or on its border, allowing for tol. With negative tol, x must
+// be inside by at least -tol.
+func (pt *planarTriangle) contains(x Vec3, tol float64) bool {
+	for e := 0; e < 3; e++ {
+		if pt.edgeNormals[e].Dot(x.Diff(pt.t.Vertices[e])) < -tol {
+			return false
+		}
+	}
+	return true
+}
+
+// SelfIntersections returns all pairs of triangles intersecting each other.
+// Triangles sharing an edge are not reported, and triangles sharing
+// only a vertex are only reported if they intersect somewhere else, too.
+// Degenerate triangles are skipped, they are reported by Validate. The
+// result is ordered by triangle indices.
+//
+// The candidate pairs are found using a bounding volume hierarchy, and tested
+// in parallel.
+func (s *Solid) SelfIntersections() []SelfIntersection {
+	tree := newBVH(s.Triangles)
+	planar := make([]planarTriangle, len(s.Triangles))
+	for i := range s.Triangles {
+		planar[i] = newPlanarTriangle(&s.Triangles[i])
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	results := make([][]SelfIntersection, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(s.Triangles); i += workers {
+				if planar[i].normal == Vec3Zero {
+					continue
+				}
+				tree.queryBox(tree.boxes[i], func(j int) bool {
+					if j <= i || planar[j].normal == Vec3Zero {
+						return true
+					}
+					if si, found := intersectTriangles(&planar[i], &planar[j]); found {
+						si.Triangles = [2]int{i, j}
+						results[w] = append(results[w], si)
+					}
+					return true
+				})
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var intersections []SelfIntersection
+	for _, r := range results {
+		intersections = append(intersections, r...)
+	}
+	sort.Slice(intersections, func(i, j int) bool {
+		a, b := intersections[i].Triangles, intersections[j].Triangles
+		return a[0] < b[0] || (a[0] == b[0] && a[1] < b[1])
+	})
+	return intersections
+}
+
+// sharedVertices returns the number of vertices of a that also are vertices
+// of b, and one of these vertices.
+func sharedVertices(a, b *Triangle) (count int, shared Vec3) {
+	for _, v := range a.Vertices {
+		if v == b.Vertices[0] || v == b.Vertices[1] || v == b.Vertices[2] {
+			count++
+			shared = v
+		}
+	}
+	return
+}
+
+// intersectTriangles tests whether a and b intersect, apart from shared
+// vertices and edges.
+func intersectTriangles(a, b *planarTriangle) (si SelfIntersection, found bool) {
+	sharedCount, shared := sharedVertices(a.t, b.t)
+	if sharedCount >= 2 {
+		return
+	}
+	tol := relativeIntersectionTolerance * max(a.size, b.size)
+
+	// quick rejection if all vertices of one triangle are on the same side
+	// of the other one's plane
+	if sameSide(a, b, tol) || sameSide(b, a, tol) {
+		return
+	}
+
+	if math.Abs(a.normal.Dot(b.normal)) > 1-relativeIntersectionTolerance &&
+		math.Abs(a.distance(b.t.Vertices[0])) <= tol {
+		si.Coplanar = true
+		found = coplanarOverlap(a, b, sharedCount == 1, shared, tol)
+		return
+	}
+
+	var points [8]Vec3
+	n := 0
+	for e := 0; e < 3; e++ {
+		n = segmentIntersection(points[:], n, a.t.Vertices[e], a.t.Vertices[(e+1)%3], b, tol)
+		n = segmentIntersection(points[:], n, b.t.Vertices[e], b.t.Vertices[(e+1)%3], a, tol)
+	}
+	if sharedCount == 1 && n < len(points) {
+		points[n] = shared
+		n++
+	}
+	if n < 2 {
+		return
+	}
+
+	// the points lie on the intersection line of both planes, find the extremes
+	dir := a.normal.Cross(b.normal)
+	lo, hi := 0, 0
+	for i, p := range points[:n] {
+		if p.Dot(dir) < points[lo].Dot(dir) {
+			lo = i
+		}
+		if p.Dot(dir) > points[hi].Dot(dir) {
+			hi = i
+		}
+	}
+	segment := [2]Vec3{points[lo], points[hi]}
+	if segment[1].Diff(segment[0]).Len() <= tol {
+		// touching in a single point, e.g. the shared vertex
+		return
+	}
+	if segmentOnEdge(segment, a.t, tol) && segmentOnEdge(segment, b.t, tol) {
+		// touching along the edges, e.g. at a T-junction
+		return
+	}
+	si.Segment = segment
+	found = true
+	return
+}
+
+// segmentOnEdge is true if both ends of segment lie on the same edge of t,
+// allowing for tol.
+func segmentOnEdge(segment [2]Vec3, t *Triangle, tol float64) bool {
+	for e := 0; e < 3; e++ {
+		v, w := t.Vertices[e], t.Vertices[(e+1)%3]
+		if pointSegmentDistance(segment[0], v, w) <= tol &&
+			pointSegmentDistance(segment[1], v, w) <= tol {
+			return true
+		}
+	}
+	return false
+}
+
+// pointSegmentDistance returns the distance between p and the segment v -> w.
+func pointSegmentDistance(p, v, w Vec3) float64 {
+	edge := w.Diff(v)
+	l2 := edge.Dot(edge)
+	if l2 == 0 {
+		return p.Diff(v).Len()
+	}
+	t := math.Max(0, math.Min(1, p.Diff(v).Dot(edge)/l2))
+	return v.Add(edge.MultScalar(t)).Diff(p).Len()
+}
+
+// sameSide is true if all vertices of b are strictly on the same side of
+// the plane of a, allowing for tol.
+func sameSide(a, b *planarTriangle, tol float64) bool {
+	positive, negative := 0, 0
+	for _, v := range b.t.Vertices {
+		d := a.distance(v)
+		if d > tol {
+			positive++
+		} else if d < -tol {
+			negative++
+		}
+	}
+	return positive == 3 || negative == 3
+}
+
+// segmentIntersection stores the points where the segment p -> q meets
+// triangle t in points[n:], and returns the new number of points.
+func segmentIntersection(points []Vec3, n int, p, q Vec3, t *planarTriangle, tol float64) int {
+	add := func(x Vec3) {
+		if n < len(points) && t.contains(x, tol) {
+			points[n] = x
+			n++
+		}
+	}
+	dp, dq := t.distance(p), t.distance(q)
+	switch {
+	case math.Abs(dp) <= tol && math.Abs(dq) <= tol:
+		// segment lies in the plane of t, its intersections with the
+		// other edges are found from the other side
+		add(p)
+		add(q)
+	case math.Abs(dp) <= tol:
+		add(p)
+	case math.Abs(dq) <= tol:
+		add(q)
+	case (dp > 0) != (dq > 0):
+		add(p.Add(q.Diff(p).MultScalar(dp / (dp - dq))))
+	}
+	return n
+}
+
+// coplanarOverlap is true if the coplanar triangles a and b overlap in more
+// than the shared vertex, if hasShared is true.
+func coplanarOverlap(a, b *planarTriangle, hasShared bool, shared Vec3, tol float64) bool {
+	// a vertex strictly inside the other triangle
+	for _, pair := range [2][2]*planarTriangle{{a, b}, {b, a}} {
+		for _, v := range pair[0].t.Vertices {
+			if !(hasShared && v == shared) && pair[1].contains(v, -tol) {
+				return true
+			}
+		}
+	}
+	// edges crossing each other
+	for e := 0; e < 3; e++ {
+		for f := 0; f < 3; f++ {
+			if edgesCross(a, e, b, f, tol) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// edgesCross is true if edge e of a and edge f of b, lying in the same plane,
+// properly cross each other.
+func edgesCross(a *planarTriangle, e int, b *planarTriangle, f int, tol float64) bool {
+	p, q := a.t.Vertices[e], a.t.Vertices[(e+1)%3]
+	r, s := b.t.Vertices[f], b.t.Vertices[(f+1)%3]
+	d1, d2 := a.edgeNormals[e].Dot(r.Diff(p)), a.edgeNormals[e].Dot(s.Diff(p))
+	d3, d4 := b.edgeNormals[f].Dot(p.Diff(r)), b.edgeNormals[f].Dot(q.Diff(r))
+	return ((d1 > tol && d2 < -tol) || (d1 < -tol && d2 > tol)) &&
+		((d3 > tol && d4 < -tol) || (d3 < -tol && d4 > tol))
+}
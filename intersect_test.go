@@ -0,0 +1,79 @@
+package stl
+
+// Tests for the detection of self-intersections.
+
+import (
+	"testing"
+)
+
+func TestSelfIntersections_None(t *testing.T) {
+	if si := makeTestSolid().SelfIntersections(); len(si) != 0 {
+		t.Errorf("Expected no self-intersections, found %v", si)
+	}
+	torus := makeTorusTestSolid(2, 0.5, 16, 12)
+	torus.WeldVertices(0.000001)
+	if si := torus.SelfIntersections(); len(si) != 0 {
+		t.Errorf("Expected no self-intersections in torus, found %v", si)
+	}
+	if si := makeTJunctionTestSolid().SelfIntersections(); len(si) != 0 {
+		t.Errorf("Expected no self-intersections at T-junction, found %v", si)
+	}
+	// Touching in a vertex only
+	s := makeTestSolid()
+	moved := makeTestSolid()
+	moved.Translate(Vec3{1, 0, 0})
+	s.Triangles = append(s.Triangles, moved.Triangles...)
+	if si := s.SelfIntersections(); len(si) != 0 {
+		t.Errorf("Expected no self-intersections for touching solids, found %v", si)
+	}
+}
+
+func TestSelfIntersections(t *testing.T) {
+	s := &Solid{}
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}}})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0.5, 0.5, -1}, {0.5, 0.5, 1}, {3, 3, 0}}})
+	si := s.SelfIntersections()
+	if len(si) != 1 {
+		t.Fatalf("Expected 1 self-intersection, found %v", si)
+	}
+	if si[0].Triangles != [2]int{0, 1} || si[0].Coplanar {
+		t.Errorf("Self-intersection not as expected: %+v", si[0])
+	}
+	seg := si[0].Segment
+	expected := [2]Vec3{{0.5, 0.5, 0}, {1, 1, 0}}
+	if !(seg[0].AlmostEqual(expected[0], 0.000001) && seg[1].AlmostEqual(expected[1], 0.000001)) &&
+		!(seg[0].AlmostEqual(expected[1], 0.000001) && seg[1].AlmostEqual(expected[0], 0.000001)) {
+		t.Errorf("Expected segment %v, found %v", expected, seg)
+	}
+}
+
+func TestSelfIntersections_SharedVertex(t *testing.T) {
+	s := &Solid{}
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}}})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {1, 1, 1}, {1, 1, -1}}})
+	if si := s.SelfIntersections(); len(si) != 1 {
+		t.Errorf("Expected 1 self-intersection, found %v", si)
+	}
+}
+
+func TestSelfIntersections_Coplanar(t *testing.T) {
+	s := &Solid{}
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}}})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0.5, 0.5, 0}, {3, 0.5, 0}, {0.5, 3, 0}}})
+	si := s.SelfIntersections()
+	if len(si) != 1 || !si[0].Coplanar {
+		t.Errorf("Expected 1 coplanar self-intersection, found %v", si)
+	}
+}
+
+func BenchmarkSelfIntersections(b *testing.B) {
+	b.StopTimer()
+	solid, err := ReadFile(testFilenameComplexBinary)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		solid.SelfIntersections()
+	}
+}
@@ -0,0 +1,191 @@
+package stl
+
+// This file contains the calculation of volume, surface area and inertia.
+
+import (
+	"math"
+	"sort"
+)
+
+// MassProperties is the result of Solid.MassProperties.
+type MassProperties struct {
+	// SurfaceArea is the sum of the triangle areas.
+	SurfaceArea float64
+
+	// Volume is the signed volume enclosed by the triangles. It is negative
+	// if the triangles are oriented inside out.
+	Volume float64
+
+	// Mass is Volume multiplied with the density.
+	Mass float64
+
+	// CenterOfMass is the centroid of the enclosed volume. For solids without
+	// volume, the centroid of the surface is used.
+	CenterOfMass Vec3
+
+	// Inertia is the inertia tensor about CenterOfMass, multiplied with
+	// the density. It is given by rows, i.e. Inertia[row][column].
+	Inertia [3]Vec3
+
+	// PrincipalMoments are the eigenvalues of Inertia in ascending order.
+	PrincipalMoments Vec3
+
+	// PrincipalAxes are the unit eigenvectors of Inertia belonging to
+	// PrincipalMoments. They form a right-handed coordinate system.
+	PrincipalAxes [3]Vec3
+
+	// IsClosed is false if the solid is not watertight, so the volume
+	// and everything derived from it are not well-defined. Vertices are
+	// compared exactly to determine this.
+	IsClosed bool
+}
+
+// MassProperties calculates the volume, mass and inertia of the solid with
+// the given density, using the divergence theorem over the triangles. The
+// results are only meaningful for closed solids, see MassProperties.IsClosed.
+func (s *Solid) MassProperties(density float64) MassProperties {
+	props := MassProperties{
+		PrincipalAxes: [3]Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+		IsClosed:      s.ToIndexed(0).isClosed(),
+	}
+	if len(s.Triangles) == 0 {
+		return props
+	}
+
+	// Integrals of 1, x, y, z, x², y², z², xy, yz, zx over the volume,
+	// following David Eberly, "Polyhedral Mass Properties (Revisited)".
+	// The coordinates are taken relative to a vertex to reduce rounding errors.
+	origin := s.Triangles[0].Vertices[0]
+	var integral [10]float64
+	var areaCentroid Vec3
+	for i := range s.Triangles {
+		t := &s.Triangles[i]
+		p0, p1, p2 := t.Vertices[0].Diff(origin), t.Vertices[1].Diff(origin), t.Vertices[2].Diff(origin)
+		d := p1.Diff(p0).Cross(p2.Diff(p0))
+		area := d.Len() / 2
+		props.SurfaceArea += area
+		areaCentroid = areaCentroid.Add(p0.Add(p1).Add(p2).MultScalar(area / 3))
+
+		var f1, f2, f3, g0, g1, g2 Vec3
+		for a := 0; a < 3; a++ {
+			f1[a], f2[a], f3[a], g0[a], g1[a], g2[a] = volumeSubexpressions(p0[a], p1[a], p2[a])
+		}
+		integral[0] += d[0] * f1[0]
+		integral[1] += d[0] * f2[0]
+		integral[2] += d[1] * f2[1]
+		integral[3] += d[2] * f2[2]
+		integral[4] += d[0] * f3[0]
+		integral[5] += d[1] * f3[1]
+		integral[6] += d[2] * f3[2]
+		integral[7] += d[0] * (p0[1]*g0[0] + p1[1]*g1[0] + p2[1]*g2[0])
+		integral[8] += d[1] * (p0[2]*g0[1] + p1[2]*g1[1] + p2[2]*g2[1])
+		integral[9] += d[2] * (p0[0]*g0[2] + p1[0]*g1[2] + p2[0]*g2[2])
+	}
+	for i, factor := range [10]float64{1. / 6, 1. / 24, 1. / 24, 1. / 24, 1. / 60, 1. / 60, 1. / 60, 1. / 120, 1. / 120, 1. / 120} {
+		integral[i] *= factor
+	}
+
+	volume := integral[0]
+	props.Volume = volume
+	props.Mass = density * volume
+	if volume == 0 {
+		if props.SurfaceArea > 0 {
+			props.CenterOfMass = origin.Add(areaCentroid.MultScalar(1 / props.SurfaceArea))
+		} else {
+			props.CenterOfMass = origin
+		}
+		return props
+	}
+
+	c := Vec3{integral[1] / volume, integral[2] / volume, integral[3] / volume}
+	props.CenterOfMass = origin.Add(c)
+	xx := integral[5] + integral[6] - volume*(c[1]*c[1]+c[2]*c[2])
+	yy := integral[4] + integral[6] - volume*(c[2]*c[2]+c[0]*c[0])
+	zz := integral[4] + integral[5] - volume*(c[0]*c[0]+c[1]*c[1])
+	xy := -(integral[7] - volume*c[0]*c[1])
+	yz := -(integral[8] - volume*c[1]*c[2])
+	zx := -(integral[9] - volume*c[2]*c[0])
+	props.Inertia = [3]Vec3{
+		{xx, xy, zx},
+		{xy, yy, yz},
+		{zx, yz, zz},
+	}
+	for r := range props.Inertia {
+		props.Inertia[r] = props.Inertia[r].MultScalar(density)
+	}
+	props.PrincipalMoments, props.PrincipalAxes = symmetricEigen(props.Inertia)
+	return props
+}
+
+// volumeSubexpressions calculates the terms of the volume integrals
+// depending on a single coordinate w of the three vertices.
+func volumeSubexpressions(w0, w1, w2 float64) (f1, f2, f3, g0, g1, g2 float64) {
+	temp0 := w0 + w1
+	f1 = temp0 + w2
+	temp1 := w0 * w0
+	temp2 := temp1 + w1*temp0
+	f2 = temp2 + w2*f1
+	f3 = w0*temp1 + w1*temp2 + w2*f2
+	g0 = f2 + w0*(f1+w0)
+	g1 = f2 + w1*(f1+w1)
+	g2 = f2 + w2*(f1+w2)
+	return
+}
+
+// Parameters of the Jacobi eigenvalue algorithm
+const (
+	jacobiMaxSweeps = 50
+	jacobiEpsilon   = 1e-15
+)
+
+// symmetricEigen calculates the eigenvalues of the symmetric matrix a,
+// given by rows, in ascending order, and the corresponding unit eigenvectors
+// forming a right-handed coordinate system, using the Jacobi algorithm.
+func symmetricEigen(a [3]Vec3) (values Vec3, vectors [3]Vec3) {
+	// v contains the eigenvectors as columns
+	v := [3]Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	for sweep := 0; sweep < jacobiMaxSweeps; sweep++ {
+		offDiagonal := math.Abs(a[0][1]) + math.Abs(a[0][2]) + math.Abs(a[1][2])
+		scale := math.Abs(a[0][0]) + math.Abs(a[1][1]) + math.Abs(a[2][2])
+		if offDiagonal <= jacobiEpsilon*scale || offDiagonal == 0 {
+			break
+		}
+		for _, pq := range [3][2]int{{0, 1}, {0, 2}, {1, 2}} {
+			p, q := pq[0], pq[1]
+			if a[p][q] == 0 {
+				continue
+			}
+			// rotation annihilating a[p][q]
+			theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+			t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+			if theta < 0 {
+				t = -t
+			}
+			c := 1 / math.Sqrt(t*t+1)
+			s := t * c
+			for k := 0; k < 3; k++ {
+				akp, akq := a[k][p], a[k][q]
+				a[k][p], a[k][q] = c*akp-s*akq, s*akp+c*akq
+			}
+			for k := 0; k < 3; k++ {
+				apk, aqk := a[p][k], a[q][k]
+				a[p][k], a[q][k] = c*apk-s*aqk, s*apk+c*aqk
+			}
+			for k := 0; k < 3; k++ {
+				vkp, vkq := v[k][p], v[k][q]
+				v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+			}
+		}
+	}
+
+	order := []int{0, 1, 2}
+	sort.Slice(order, func(i, j int) bool {
+		return a[order[i]][order[i]] < a[order[j]][order[j]]
+	})
+	for i, k := range order {
+		values[i] = a[k][k]
+		vectors[i] = Vec3{v[0][k], v[1][k], v[2][k]}.UnitVec3()
+	}
+	vectors[2] = vectors[0].Cross(vectors[1])
+	return
+}
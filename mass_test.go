@@ -0,0 +1,107 @@
+package stl
+
+// Tests for the calculation of mass properties.
+
+import (
+	"math"
+	"testing"
+)
+
+// makeBoxTestSolid returns a closed box from the origin to size.
+func makeBoxTestSolid(size Vec3) *Solid {
+	corner := func(i int) Vec3 {
+		return Vec3{float64(i&1) * size[0], float64(i>>1&1) * size[1], float64(i>>2&1) * size[2]}
+	}
+	// two triangles per face, counter-clockwise when looking from outside
+	quads := [6][4]int{
+		{0, 2, 3, 1}, {4, 5, 7, 6}, // bottom, top
+		{0, 1, 5, 4}, {2, 6, 7, 3}, // front, back
+		{0, 4, 6, 2}, {1, 3, 7, 5}, // left, right
+	}
+	var s Solid
+	for _, q := range quads {
+		for _, t := range [2][3]int{{0, 1, 2}, {0, 2, 3}} {
+			tri := Triangle{Vertices: [3]Vec3{corner(q[t[0]]), corner(q[t[1]]), corner(q[t[2]])}}
+			tri.recalculateNormal()
+			s.AppendTriangle(tri)
+		}
+	}
+	return &s
+}
+
+func TestMassProperties_Box(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{1, 2, 3})
+	s.Translate(Vec3{10, 20, 30})
+	props := s.MassProperties(2)
+	if !props.IsClosed {
+		t.Error("Expected closed solid")
+	}
+	if !almostEqual64(props.Volume, 6, 1e-12) || !almostEqual64(props.Mass, 12, 1e-12) ||
+		!almostEqual64(props.SurfaceArea, 22, 1e-12) {
+		t.Errorf("Expected volume 6, mass 12 and area 22, found %+v", props)
+	}
+	if !props.CenterOfMass.AlmostEqual(Vec3{10.5, 21, 31.5}, 1e-12) {
+		t.Errorf("Expected center of mass [10.5 21 31.5], found %v", props.CenterOfMass)
+	}
+	expected := [3]Vec3{{13, 0, 0}, {0, 10, 0}, {0, 0, 5}}
+	for r := range expected {
+		if !props.Inertia[r].AlmostEqual(expected[r], 1e-9) {
+			t.Errorf("Expected inertia %v, found %v", expected, props.Inertia)
+		}
+	}
+	if !props.PrincipalMoments.AlmostEqual(Vec3{5, 10, 13}, 1e-9) {
+		t.Errorf("Expected principal moments [5 10 13], found %v", props.PrincipalMoments)
+	}
+
+	// rotating the box rotates the principal axes
+	s.Rotate(Vec3Zero, Vec3{1, 1, 0}.UnitVec3(), 0.7)
+	rotated := s.MassProperties(2)
+	if !rotated.PrincipalMoments.AlmostEqual(props.PrincipalMoments, 1e-9) {
+		t.Errorf("Expected principal moments %v, found %v", props.PrincipalMoments, rotated.PrincipalMoments)
+	}
+	var m Mat4
+	RotationMatrix(Vec3Zero, Vec3{1, 1, 0}.UnitVec3(), 0.7, &m)
+	for i, axis := range props.PrincipalAxes {
+		expectedAxis := m.MultVec3(axis).Diff(m.MultVec3(Vec3Zero))
+		if math.Abs(math.Abs(expectedAxis.Dot(rotated.PrincipalAxes[i]))-1) > 1e-9 {
+			t.Errorf("Expected principal axis %d parallel to %v, found %v", i, expectedAxis, rotated.PrincipalAxes[i])
+		}
+	}
+	axes := rotated.PrincipalAxes
+	if !axes[0].Cross(axes[1]).AlmostEqual(axes[2], 1e-12) {
+		t.Errorf("Expected right-handed axes, found %v", axes)
+	}
+}
+
+func TestMassProperties_Tetrahedron(t *testing.T) {
+	props := makeTestSolid().MassProperties(1)
+	if !almostEqual64(props.Volume, 1.0/6, 1e-12) {
+		t.Errorf("Expected volume 1/6, found %v", props.Volume)
+	}
+	if !props.CenterOfMass.AlmostEqual(Vec3{0.25, 0.25, 0.25}, 1e-12) {
+		t.Errorf("Expected center of mass [0.25 0.25 0.25], found %v", props.CenterOfMass)
+	}
+	// Ixx about the origin is 1/30, Ixy is -1/120
+	expectedXX := 1.0/30 - 1.0/6*(2*0.25*0.25)
+	expectedXY := -1.0/120 + 1.0/6*0.25*0.25
+	if !almostEqual64(props.Inertia[0][0], expectedXX, 1e-12) || !almostEqual64(props.Inertia[0][1], expectedXY, 1e-12) {
+		t.Errorf("Expected Ixx %v and Ixy %v, found %v", expectedXX, expectedXY, props.Inertia)
+	}
+
+	s := makeTestSolid()
+	s.Triangles = s.Triangles[1:]
+	if props := s.MassProperties(1); props.IsClosed {
+		t.Error("Expected open solid")
+	}
+}
+
+func TestMassProperties_Empty(t *testing.T) {
+	var s Solid
+	props := s.MassProperties(1)
+	if props.Volume != 0 || props.SurfaceArea != 0 || props.CenterOfMass != Vec3Zero {
+		t.Errorf("Expected no volume, area and center of mass, found %+v", props)
+	}
+	if props.PrincipalAxes != [3]Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}} {
+		t.Errorf("Expected coordinate axes as principal axes, found %v", props.PrincipalAxes)
+	}
+}
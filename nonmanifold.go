@@ -0,0 +1,192 @@
+package stl
+
+// This file contains the analysis and splitting of non-manifold vertices
+// and edges.
+
+import (
+	"math"
+	"sort"
+)
+
+// vertexFans returns for each of vertices the corners around it, grouped by
+// fan, using the corner sets from edgeUses or splitCorners. The fans are
+// ordered by their first corner.
+func (m *IndexedMesh) vertexFans(corners disjointSet, vertices map[uint32]int) map[uint32][][]int {
+	fans := make(map[uint32][][]int, len(vertices))
+	fanIndex := make(map[int]int)
+	for c := range corners {
+		v := m.Faces[c/3][c%3]
+		if _, found := vertices[v]; !found {
+			continue
+		}
+		root := corners.find(c)
+		i, found := fanIndex[root]
+		if !found {
+			i = len(fans[v])
+			fanIndex[root] = i
+			fans[v] = append(fans[v], nil)
+		}
+		fans[v][i] = append(fans[v][i], c)
+	}
+	return fans
+}
+
+// appendFaces appends the faces of corners to faces, skipping a face
+// if it is equal to the last one appended.
+func appendFaces(faces []int, corners []int) []int {
+	for _, c := range corners {
+		if len(faces) == 0 || faces[len(faces)-1] != c/3 {
+			faces = append(faces, c/3)
+		}
+	}
+	return faces
+}
+
+// halfEdge is the edge of a face from corner edge to corner (edge+1)%3.
+type halfEdge struct {
+	face, edge int
+}
+
+// splitCorners is like the corner sets returned by edgeUses, but only merges
+// the corners of two faces along every edge. The faces of an edge used by
+// more than two of them are sorted by their angle around the edge, and a
+// face using it backward is paired with the next face using it forward, as
+// they enclose the same part of the volume.
+func (m *IndexedMesh) splitCorners() disjointSet {
+	edges := make(map[[2]uint32][]halfEdge, 3*len(m.Faces)/2)
+	for i, f := range m.Faces {
+		for e := 0; e < 3; e++ {
+			if f[e] != f[(e+1)%3] {
+				key := undirectedEdge(f[e], f[(e+1)%3])
+				edges[key] = append(edges[key], halfEdge{face: i, edge: e})
+			}
+		}
+	}
+
+	corners := newDisjointSet(3 * len(m.Faces))
+	join := func(a, b halfEdge) {
+		f := m.Faces[a.face]
+		v, w := f[a.edge], f[(a.edge+1)%3]
+		corners.union(3*a.face+a.edge, m.cornerOf(b.face, v))
+		corners.union(3*a.face+(a.edge+1)%3, m.cornerOf(b.face, w))
+	}
+	for key, halfEdges := range edges {
+		if len(halfEdges) == 2 {
+			join(halfEdges[0], halfEdges[1])
+			continue
+		}
+		m.sortAroundEdge(key, halfEdges)
+		for i, h := range halfEdges {
+			next := halfEdges[(i+1)%len(halfEdges)]
+			if m.Faces[h.face][h.edge] != key[0] && m.Faces[next.face][next.edge] == key[0] {
+				join(h, next)
+			}
+		}
+	}
+	return corners
+}
+
+// sortAroundEdge sorts the faces of halfEdges, which all use the edge key,
+// by their angle around the edge from key[0] to key[1], starting at the
+// first one. Faces with the same angle keep their order.
+func (m *IndexedMesh) sortAroundEdge(key [2]uint32, halfEdges []halfEdge) {
+	a := m.Vertices[key[0]]
+	axis := m.Vertices[key[1]].Diff(a).UnitVec3()
+	angles := make(map[halfEdge]float64, len(halfEdges))
+	var reference Vec3
+	for i, h := range halfEdges {
+		// the direction from the edge to the opposite vertex
+		u := m.Vertices[m.Faces[h.face][(h.edge+2)%3]].Diff(a)
+		u = u.Diff(axis.MultScalar(u.Dot(axis)))
+		if i == 0 {
+			reference = u
+		}
+		angle := math.Atan2(axis.Dot(reference.Cross(u)), reference.Dot(u))
+		if angle < 0 {
+			angle += 2 * math.Pi
+		}
+		angles[h] = angle
+	}
+	sort.SliceStable(halfEdges, func(i, j int) bool {
+		return angles[halfEdges[i]] < angles[halfEdges[j]]
+	})
+}
+
+// SplitNonManifold makes the mesh manifold by duplicating vertices that
+// have more than one fan of faces around them. Edges shared by more than
+// two faces are split, too, keeping pairs of faces using them in opposite
+// directions connected. The fan containing the first face using a vertex
+// keeps it, the other ones get new vertices at the same position appended
+// to m.Vertices, in the order of the vertices they are split from and of
+// the first faces of the fans. Returns the number of vertices added.
+func (m *IndexedMesh) SplitNonManifold() int {
+	corners := m.splitCorners()
+	fansByVertex := m.vertexFans(corners, m.nonManifoldVertices(corners))
+	vertices := make([]uint32, 0, len(fansByVertex))
+	for v := range fansByVertex {
+		vertices = append(vertices, v)
+	}
+	sort.Slice(vertices, func(i, j int) bool {
+		return vertices[i] < vertices[j]
+	})
+
+	added := 0
+	for _, v := range vertices {
+		for _, fan := range fansByVertex[v][1:] {
+			w := uint32(len(m.Vertices))
+			m.Vertices = append(m.Vertices, m.Vertices[v])
+			for _, c := range fan {
+				m.Faces[c/3][c%3] = w
+			}
+			added++
+		}
+	}
+	return added
+}
+
+// SplitNonManifold makes the solid manifold, like IndexedMesh.SplitNonManifold.
+// As the vertices of a Solid are only connected by having the same position,
+// the duplicated vertices are moved by offset towards the centers of the
+// triangles using them, so offset must be greater than 0. If these
+// directions cancel out, like in a flat fan around the vertex, it is moved
+// towards the center of the first triangle using it. Returns the number of
+// vertices added.
+func (s *Solid) SplitNonManifold(offset float64) int {
+	m := s.ToIndexed(0)
+	originalCount := len(m.Vertices)
+	added := m.SplitNonManifold()
+	if added == 0 {
+		return 0
+	}
+
+	directions := make([]Vec3, added)
+	firstDirections := make([]Vec3, added)
+	for _, f := range m.Faces {
+		center := m.Vertices[f[0]].Add(m.Vertices[f[1]]).Add(m.Vertices[f[2]]).MultScalar(1.0 / 3)
+		for _, v := range f {
+			if int(v) >= originalCount {
+				i := int(v) - originalCount
+				dir := center.Diff(m.Vertices[v])
+				if firstDirections[i] == Vec3Zero {
+					firstDirections[i] = dir
+				}
+				directions[i] = directions[i].Add(dir)
+			}
+		}
+	}
+	for i, dir := range directions {
+		v := originalCount + i
+		// cancelled out, up to rounding errors
+		if dir.Len() <= 1e-12*firstDirections[i].Len() {
+			dir = firstDirections[i]
+		}
+		m.Vertices[v] = m.Vertices[v].Add(dir.UnitVec3().MultScalar(offset))
+	}
+
+	for i, f := range m.Faces {
+		for k, v := range f {
+			s.Triangles[i].Vertices[k] = m.Vertices[v]
+		}
+	}
+	return added
+}
@@ -0,0 +1,194 @@
+package stl
+
+// Tests for the analysis and splitting of non-manifold vertices and edges.
+
+import (
+	"testing"
+)
+
+// makeBowtieTestSolid returns two tetrahedra touching in the vertex {1, 0, 0}.
+func makeBowtieTestSolid() *Solid {
+	s := makeTestSolid()
+	moved := makeTestSolid()
+	moved.Translate(Vec3{1, 0, 0})
+	s.Triangles = append(s.Triangles, moved.Triangles...)
+	return s
+}
+
+func TestValidate_NonManifoldVertex(t *testing.T) {
+	s := makeBowtieTestSolid()
+	errors := s.Validate()
+	for i := range s.Triangles {
+		hasVertex := false
+		for k, v := range s.Triangles[i].Vertices {
+			if v != (Vec3{1, 0, 0}) {
+				continue
+			}
+			hasVertex = true
+			te := errors[i]
+			if te == nil || te.VertexErrors[k] == nil || !te.VertexErrors[k].IsNonManifold() {
+				t.Errorf("Expected non-manifold vertex %d in triangle %d, found %+v", k, i, te)
+				continue
+			}
+			for _, j := range te.VertexErrors[k].OtherFanTriangles {
+				if (j < 4) == (i < 4) {
+					t.Errorf("Triangle %d reported in other fan of triangle %d", j, i)
+				}
+			}
+			if len(te.VertexErrors[k].OtherFanTriangles) != 3 {
+				t.Errorf("Expected 3 triangles in other fan of triangle %d, found %v", i, te.VertexErrors[k].OtherFanTriangles)
+			}
+		}
+		if !hasVertex && errors[i] != nil {
+			t.Errorf("Expected no errors for triangle %d, found %+v", i, errors[i])
+		}
+	}
+
+	if errors := makeTestSolid().Validate(); len(errors) != 0 {
+		t.Errorf("Expected no errors for closed solid, found %v", errors)
+	}
+}
+
+func TestIndexedMesh_SplitNonManifold(t *testing.T) {
+	m := makeBowtieTestSolid().ToIndexed(0)
+	vertexCount := len(m.Vertices)
+	if added := m.SplitNonManifold(); added != 1 || len(m.Vertices) != vertexCount+1 {
+		t.Errorf("Expected 1 vertex added, found %d", added)
+	}
+	if report := m.Topology(); !report.IsVertexManifold || !report.IsClosed || len(report.Shells) != 2 {
+		t.Errorf("Expected two closed manifold shells, found %+v", report)
+	}
+	if added := m.SplitNonManifold(); added != 0 {
+		t.Errorf("Expected manifold mesh to be unchanged, found %d vertices added", added)
+	}
+}
+
+func TestIndexedMesh_SplitNonManifold_Reproducible(t *testing.T) {
+	// a chain of tetrahedra touching in the vertices {1, 0, 0}, {2, 0, 0}
+	// and {3, 0, 0}
+	s := makeTestSolid()
+	for x := 1; x < 4; x++ {
+		moved := makeTestSolid()
+		moved.Translate(Vec3{float64(x), 0, 0})
+		s.Triangles = append(s.Triangles, moved.Triangles...)
+	}
+	original := s.ToIndexed(0)
+	expected := s.ToIndexed(0)
+	if added := expected.SplitNonManifold(); added != 3 {
+		t.Fatalf("Expected 3 vertices added, found %d", added)
+	}
+	// the fan with the first face keeps the vertex, the new vertices are
+	// in the order of the vertices they are split from
+	var last uint32
+	for w := len(original.Vertices); w < len(expected.Vertices); w++ {
+		var v uint32
+		for i := range original.Vertices {
+			if original.Vertices[i] == expected.Vertices[w] {
+				v = uint32(i)
+			}
+		}
+		if v < last {
+			t.Errorf("Expected new vertex %d after the one split from vertex %d, found it split from %d", w, last, v)
+		}
+		last = v
+		for i, f := range original.Faces {
+			if f[0] == v || f[1] == v || f[2] == v {
+				if g := expected.Faces[i]; g[0] != v && g[1] != v && g[2] != v {
+					t.Errorf("Expected first face %d of vertex %d to keep it, found %v", i, v, g)
+				}
+				break
+			}
+		}
+	}
+
+	for run := 0; run < 20; run++ {
+		m := s.ToIndexed(0)
+		m.SplitNonManifold()
+		for i := range m.Faces {
+			if m.Faces[i] != expected.Faces[i] {
+				t.Fatalf("Run %d: expected face %d to be %v, found %v", run, i, expected.Faces[i], m.Faces[i])
+			}
+		}
+	}
+}
+
+func TestSolid_SplitNonManifold(t *testing.T) {
+	s := makeBowtieTestSolid()
+	if added := s.SplitNonManifold(0.001); added != 1 {
+		t.Errorf("Expected 1 vertex added, found %d", added)
+	}
+	if report := s.Topology(0); !report.IsVertexManifold || !report.IsClosed || len(report.Shells) != 2 {
+		t.Errorf("Expected two closed manifold shells, found %+v", report)
+	}
+
+	// Three triangles sharing an edge, the tetrahedron stays closed
+	s = makeTestSolid()
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {0, 1, 0}, {-1, 0, 0}}})
+	if added := s.SplitNonManifold(0.001); added != 2 {
+		t.Errorf("Expected 2 vertices added, found %d", added)
+	}
+	report := s.Topology(0)
+	if !report.IsEdgeManifold || !report.IsVertexManifold || len(report.Shells) != 2 {
+		t.Errorf("Expected two manifold shells, found %+v", report)
+	}
+	if !report.Shells[0].IsClosed || report.Shells[1].IsClosed {
+		t.Errorf("Expected closed tetrahedron and open triangle, found %+v", report.Shells)
+	}
+}
+
+func TestIndexedMesh_SplitNonManifold_Edge(t *testing.T) {
+	// two tetrahedra sharing the edge from {0, 0, 0} to {0, 0, 1}, the
+	// second one turned by 180 degrees around it
+	s := makeTestSolid()
+	turned := makeTestSolid()
+	for i := range turned.Triangles {
+		for k := range turned.Triangles[i].Vertices {
+			v := &turned.Triangles[i].Vertices[k]
+			v[0], v[1] = -v[0], -v[1]
+		}
+	}
+	s.Triangles = append(s.Triangles, turned.Triangles...)
+
+	// the faces around the edge must be paired by their position around it,
+	// whatever their order in the mesh is
+	for shift := 0; shift < len(s.Triangles); shift++ {
+		shifted := &Solid{Triangles: append(append([]Triangle{}, s.Triangles[shift:]...), s.Triangles[:shift]...)}
+		m := shifted.ToIndexed(0)
+		if added := m.SplitNonManifold(); added != 2 {
+			t.Errorf("Shift %d: expected 2 vertices added, found %d", shift, added)
+		}
+		report := m.Topology()
+		if !report.IsEdgeManifold || !report.IsVertexManifold || !report.IsClosed || len(report.Shells) != 2 {
+			t.Errorf("Shift %d: expected two closed manifold shells, found %+v", shift, report)
+		}
+	}
+}
+
+func TestSolid_SplitNonManifold_FlatFan(t *testing.T) {
+	// a tetrahedron touching a flat disk of four triangles in its center,
+	// so the directions to the centers of the disk's triangles cancel out
+	s := makeTestSolid()
+	for i := range s.Triangles {
+		for k := range s.Triangles[i].Vertices {
+			v := &s.Triangles[i].Vertices[k]
+			v[2] += v[0] + v[1]
+		}
+	}
+	rim := []Vec3{{1, 0, 0}, {0, 1, 0}, {-1, 0, 0}, {0, -1, 0}}
+	for i := range rim {
+		s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, rim[i], rim[(i+1)%len(rim)]}})
+	}
+
+	if added := s.SplitNonManifold(0.001); added != 1 {
+		t.Errorf("Expected 1 vertex added, found %d", added)
+	}
+	report := s.Topology(0)
+	if !report.IsVertexManifold || len(report.Shells) != 2 {
+		t.Errorf("Expected two manifold shells, found %+v", report)
+	}
+	for i := 4; i < len(s.Triangles); i++ {
+		if v := s.Triangles[i].Vertices[0]; v == Vec3Zero || !v.AlmostEqual(Vec3Zero, 0.001) || v[2] != 0 {
+			t.Errorf("Expected the center of the disk to be moved by 0.001 within the disk, found %v", v)
+		}
+	}
+}
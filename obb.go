@@ -0,0 +1,311 @@
+package stl
+
+// This file contains the calculation of oriented bounding boxes.
+
+import (
+	"math"
+	"sort"
+)
+
+// OrientedBox is a box with arbitrary orientation, see
+// Solid.OrientedBoundingBox.
+type OrientedBox struct {
+	// Center of the box
+	Center Vec3
+
+	// Axes are the unit directions of the box edges, forming a right-handed
+	// coordinate system.
+	Axes [3]Vec3
+
+	// HalfExtents is half the length of the box along each axis.
+	HalfExtents Vec3
+}
+
+// Volume returns the volume of the box.
+func (b *OrientedBox) Volume() float64 {
+	return 8 * b.HalfExtents[0] * b.HalfExtents[1] * b.HalfExtents[2]
+}
+
+// AlignmentMatrix sets m to the transformation moving the center of the box
+// to the origin, and rotating its axes onto the x, y and z axis. After
+// Solid.Transform(m), the solid's Measure is the box.
+func (b *OrientedBox) AlignmentMatrix(m *Mat4) {
+	*m = Mat4Identity
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			m[r][c] = b.Axes[r][c]
+		}
+		m[r][3] = -b.Axes[r].Dot(b.Center)
+	}
+}
+
+// maxExactBoxHullFaces is the maximum number of faces of a convex hull for
+// which OrientedBoundingBox tries all of them.
+const maxExactBoxHullFaces = 4096
+
+// OrientedBoundingBox returns a box of minimal volume containing the solid
+// among the boxes having a face flush with a face of the convex hull. This
+// is the exact minimum in most cases, and a close approximation otherwise.
+// For each face of the hull, the smallest rectangle containing the hull
+// projected onto it is found using rotating calipers. This takes O(h²) time
+// for a hull with h faces, so for hulls with more than 4096 faces only the
+// principal axes of the hull's vertices are tried as normals instead. If the
+// solid is flat, the box is calculated within its plane.
+func (s *Solid) OrientedBoundingBox() OrientedBox {
+	points := s.uniqueVertices()
+	if len(points) == 0 {
+		return OrientedBox{Axes: [3]Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}}
+	}
+
+	faces, ok := convexHull(points)
+	if !ok {
+		// flat solid, use the plane with the smallest spread
+		axes := principalAxes(points)
+		return boxAroundNormal(points, points, axes[0])
+	}
+
+	// The outline of the hull seen along a face normal is formed by the
+	// edges between faces facing that direction and the other ones.
+	normals := make([]Vec3, len(faces))
+	for i, f := range faces {
+		normals[i] = points[f[1]].Diff(points[f[0]]).Cross(points[f[2]].Diff(points[f[0]])).UnitVec3()
+	}
+	var edges []hullEdge
+	edgeFace := make(map[[2]int]int, 3*len(faces))
+	var hullPoints []Vec3
+	used := make([]bool, len(points))
+	for i, f := range faces {
+		for e := 0; e < 3; e++ {
+			a, b := f[e], f[(e+1)%3]
+			if other, found := edgeFace[[2]int{b, a}]; found {
+				edges = append(edges, hullEdge{vertices: [2]int{a, b}, faces: [2]int{i, other}})
+			} else {
+				edgeFace[[2]int{a, b}] = i
+			}
+			if !used[a] {
+				used[a] = true
+				hullPoints = append(hullPoints, points[a])
+			}
+		}
+	}
+
+	var best OrientedBox
+	bestVolume := math.Inf(1)
+	if len(faces) > maxExactBoxHullFaces {
+		for _, axis := range principalAxes(hullPoints) {
+			box := boxAroundNormal(hullPoints, hullPoints, axis)
+			if volume := box.Volume(); volume < bestVolume {
+				bestVolume = volume
+				best = box
+			}
+		}
+		return best
+	}
+
+	// coplanar faces of the hull give the same box
+	done := make(map[[3]int64]bool, len(faces))
+	front := make([]bool, len(faces))
+	inOutline := make([]int, len(points))
+	for i, normal := range normals {
+		key := [3]int64{int64(math.Round(normal[0] * 1e9)), int64(math.Round(normal[1] * 1e9)), int64(math.Round(normal[2] * 1e9))}
+		if done[key] {
+			continue
+		}
+		done[key] = true
+
+		for j, n := range normals {
+			front[j] = n.Dot(normal) > 0
+		}
+		var outline []Vec3
+		for _, e := range edges {
+			if front[e.faces[0]] == front[e.faces[1]] {
+				continue
+			}
+			for _, v := range e.vertices {
+				if inOutline[v] != i+1 {
+					inOutline[v] = i + 1
+					outline = append(outline, points[v])
+				}
+			}
+		}
+
+		box := boxAroundNormal(hullPoints, outline, normal)
+		if volume := box.Volume(); volume < bestVolume {
+			bestVolume = volume
+			best = box
+		}
+	}
+	return best
+}
+
+// hullEdge is an edge of a convex hull with its two faces.
+type hullEdge struct {
+	vertices [2]int
+	faces    [2]int
+}
+
+// OrientedBoundingBoxPCA returns a box containing the solid, whose axes are
+// the principal axes of its vertices. This is fast, but the box may be
+// considerably larger than the one returned by OrientedBoundingBox.
+func (s *Solid) OrientedBoundingBoxPCA() OrientedBox {
+	points := s.uniqueVertices()
+	axes := principalAxes(points)
+	return boxWithAxes(points, [3]Vec3{axes[2], axes[1], axes[2].Cross(axes[1])})
+}
+
+// uniqueVertices returns the distinct vertices of the solid.
+func (s *Solid) uniqueVertices() []Vec3 {
+	return s.ToIndexed(0).Vertices
+}
+
+// principalAxes returns the eigenvectors of the covariance matrix of points,
+// in ascending order of the variance along them.
+func principalAxes(points []Vec3) [3]Vec3 {
+	var mean Vec3
+	for _, p := range points {
+		mean = mean.Add(p)
+	}
+	mean = mean.MultScalar(1 / math.Max(1, float64(len(points))))
+	var covariance [3]Vec3
+	for _, p := range points {
+		d := p.Diff(mean)
+		for r := 0; r < 3; r++ {
+			covariance[r] = covariance[r].Add(d.MultScalar(d[r]))
+		}
+	}
+	_, axes := symmetricEigen(covariance)
+	return axes
+}
+
+// boxWithAxes returns the smallest box with the given axes containing points.
+func boxWithAxes(points []Vec3, axes [3]Vec3) OrientedBox {
+	lo := Vec3{math.Inf(1), math.Inf(1), math.Inf(1)}
+	hi := lo.MultScalar(-1)
+	for _, p := range points {
+		for a := 0; a < 3; a++ {
+			d := axes[a].Dot(p)
+			lo[a] = math.Min(lo[a], d)
+			hi[a] = math.Max(hi[a], d)
+		}
+	}
+	box := OrientedBox{Axes: axes}
+	if len(points) == 0 {
+		return box
+	}
+	for a := 0; a < 3; a++ {
+		box.Center = box.Center.Add(axes[a].MultScalar((lo[a] + hi[a]) / 2))
+		box.HalfExtents[a] = (hi[a] - lo[a]) / 2
+	}
+	return box
+}
+
+// boxAroundNormal returns the smallest box containing points with one axis
+// being normal, finding the other two using rotating calipers. outline
+// contains the points forming the outline of points seen along normal,
+// which may be all of them.
+func boxAroundNormal(points, outline []Vec3, normal Vec3) OrientedBox {
+	// orthonormal basis u, v of the plane
+	u := Vec3{1, 0, 0}
+	if math.Abs(normal[0]) > 0.9 {
+		u = Vec3{0, 1, 0}
+	}
+	u = u.Diff(normal.MultScalar(u.Dot(normal))).UnitVec3()
+	v := normal.Cross(u)
+
+	projected := make([][2]float64, len(outline))
+	for i, p := range outline {
+		projected[i] = [2]float64{u.Dot(p), v.Dot(p)}
+	}
+	direction := minimumAreaRectangle(convexHull2D(projected))
+	axis0 := u.MultScalar(direction[0]).Add(v.MultScalar(direction[1]))
+	return boxWithAxes(points, [3]Vec3{axis0, normal.Cross(axis0), normal})
+}
+
+// convexHull2D returns the convex hull of points in counter-clockwise order,
+// using Andrew's monotone chain algorithm.
+func convexHull2D(points [][2]float64) [][2]float64 {
+	sorted := append([][2]float64(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i][0] < sorted[j][0] || (sorted[i][0] == sorted[j][0] && sorted[i][1] < sorted[j][1])
+	})
+	if len(sorted) < 3 {
+		return sorted
+	}
+	cross := func(o, a, b [2]float64) float64 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+	hull := make([][2]float64, 0, 2*len(sorted))
+	for _, p := range sorted {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for i := len(sorted) - 2; i >= 0; i-- {
+		p := sorted[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	return hull[:len(hull)-1]
+}
+
+// minimumAreaRectangle returns the unit direction of one side of the
+// rectangle of minimal area containing the convex polygon hull, given in
+// counter-clockwise order, using rotating calipers.
+func minimumAreaRectangle(hull [][2]float64) [2]float64 {
+	n := len(hull)
+	best := [2]float64{1, 0}
+	if n < 2 {
+		return best
+	}
+	dot := func(a, b [2]float64) float64 {
+		return a[0]*b[0] + a[1]*b[1]
+	}
+	extreme := func(value func(p [2]float64) float64) int {
+		best := 0
+		for i, p := range hull {
+			if value(p) > value(hull[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	advance := func(i int, value func(p [2]float64) float64) int {
+		for value(hull[(i+1)%n]) > value(hull[i]) {
+			i = (i + 1) % n
+		}
+		return i
+	}
+
+	bestArea := math.Inf(1)
+	// indices of the points extreme along the edge direction, its normal,
+	// and against the edge direction, which only move forward
+	right, top, left := -1, -1, -1
+	for i := 0; i < n; i++ {
+		p, q := hull[i], hull[(i+1)%n]
+		length := math.Hypot(q[0]-p[0], q[1]-p[1])
+		if length == 0 {
+			continue
+		}
+		dir := [2]float64{(q[0] - p[0]) / length, (q[1] - p[1]) / length}
+		normal := [2]float64{-dir[1], dir[0]}
+		along := func(p [2]float64) float64 { return dot(p, dir) }
+		across := func(p [2]float64) float64 { return dot(p, normal) }
+		against := func(p [2]float64) float64 { return -dot(p, dir) }
+		if right < 0 {
+			right, top, left = extreme(along), extreme(across), extreme(against)
+		} else {
+			right, top, left = advance(right, along), advance(top, across), advance(left, against)
+		}
+		width := dot(hull[right], dir) - dot(hull[left], dir)
+		height := dot(hull[top], normal) - dot(p, normal)
+		if area := width * height; area < bestArea {
+			bestArea = area
+			best = dir
+		}
+	}
+	return best
+}
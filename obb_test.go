@@ -0,0 +1,90 @@
+package stl
+
+// Tests for the calculation of oriented bounding boxes.
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func sortedExtents(b *OrientedBox) []float64 {
+	e := []float64{b.HalfExtents[0], b.HalfExtents[1], b.HalfExtents[2]}
+	sort.Float64s(e)
+	return e
+}
+
+func TestOrientedBoundingBox_RotatedBox(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{1, 2, 3})
+	s.Rotate(Vec3{1, 2, 3}, Vec3{1, -2, 0.5}.UnitVec3(), 0.9)
+	s.Translate(Vec3{-4, 5, 6})
+
+	box := s.OrientedBoundingBox()
+	extents := sortedExtents(&box)
+	for i, expected := range []float64{0.5, 1, 1.5} {
+		if !almostEqual64(extents[i], expected, 1e-9) {
+			t.Errorf("Expected half extents [0.5 1 1.5], found %v", box.HalfExtents)
+			break
+		}
+	}
+	if !almostEqual64(box.Volume(), 6, 1e-9) {
+		t.Errorf("Expected volume 6, found %v", box.Volume())
+	}
+	if !box.Axes[0].Cross(box.Axes[1]).AlmostEqual(box.Axes[2], 1e-12) {
+		t.Errorf("Expected right-handed axes, found %v", box.Axes)
+	}
+
+	var m Mat4
+	box.AlignmentMatrix(&m)
+	s.Transform(&m)
+	measure := s.Measure()
+	if !measure.Min.AlmostEqual(box.HalfExtents.MultScalar(-1), 1e-9) ||
+		!measure.Max.AlmostEqual(box.HalfExtents, 1e-9) {
+		t.Errorf("Expected aligned solid to span %v, found %+v", box.HalfExtents, measure)
+	}
+}
+
+func TestOrientedBoundingBox_Torus(t *testing.T) {
+	s := makeTorusTestSolid(2, 0.5, 24, 12)
+	s.Rotate(Vec3Zero, Vec3{1, 1, 1}.UnitVec3(), 0.4)
+	measure := s.Measure()
+	aabbVolume := measure.Len[0] * measure.Len[1] * measure.Len[2]
+	for name, box := range map[string]OrientedBox{
+		"exact": s.OrientedBoundingBox(),
+		"PCA":   s.OrientedBoundingBoxPCA(),
+	} {
+		if box.Volume() > aabbVolume {
+			t.Errorf("%s: Expected volume smaller than %v, found %v", name, aabbVolume, box.Volume())
+		}
+		if !box.Axes[0].Cross(box.Axes[1]).AlmostEqual(box.Axes[2], 1e-9) {
+			t.Errorf("%s: Expected right-handed axes, found %v", name, box.Axes)
+		}
+		for _, tri := range s.Triangles {
+			for _, v := range tri.Vertices {
+				d := v.Diff(box.Center)
+				for a := 0; a < 3; a++ {
+					if math.Abs(d.Dot(box.Axes[a])) > box.HalfExtents[a]+1e-9 {
+						t.Fatalf("%s: Vertex %v outside of box %+v", name, v, box)
+					}
+				}
+			}
+		}
+	}
+	// the flat side of the torus is 1 thick
+	box := s.OrientedBoundingBox()
+	if extents := sortedExtents(&box); !almostEqual64(extents[0], 0.5, 1e-9) {
+		t.Errorf("Expected smallest half extent 0.5, found %v", box.HalfExtents)
+	}
+}
+
+func TestOrientedBoundingBox_Flat(t *testing.T) {
+	s := &Solid{}
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {4, 0, 0}, {1, 1, 0}}})
+	s.Rotate(Vec3Zero, Vec3{0, 1, 1}.UnitVec3(), 0.3)
+	box := s.OrientedBoundingBox()
+	// the smallest rectangle is aligned with the longest side
+	extents := sortedExtents(&box)
+	if extents[0] > 1e-12 || !almostEqual64(extents[1], 0.5, 1e-9) || !almostEqual64(extents[2], 2, 1e-9) {
+		t.Errorf("Expected half extents [0 0.5 2], found %v", box.HalfExtents)
+	}
+}
@@ -0,0 +1,136 @@
+package stl
+
+// This file contains the repair of triangle orientation.
+
+// faceEdge refers to the use of an undirected edge by a face.
+type faceEdge struct {
+	face int
+
+	// forward is true if the face uses the edge from the lower
+	// to the higher vertex index.
+	forward bool
+}
+
+// FixOrientation flips triangles so that neighboring triangles are oriented
+// consistently, i.e. share their edges in opposite directions. This is done
+// for every shell by walking from triangle to triangle across edges shared
+// by exactly two triangles. Afterwards closed shells are turned
+// inside out if necessary, so their normals point outside, as determined by
+// the sign of the enclosed volume. In open shells the orientation of the
+// majority of triangles is kept. Returns the number of triangles flipped.
+func (s *Solid) FixOrientation() int {
+	flipped := 0
+	for i, flip := range s.ToIndexed(0).orientationFlips() {
+		if flip {
+			s.Triangles[i].flip()
+			flipped++
+		}
+	}
+	return flipped
+}
+
+// FixOrientation flips faces like Solid.FixOrientation, swapping their
+// vertex indices and inverting their normals. Returns the number of faces
+// flipped.
+func (m *IndexedMesh) FixOrientation() int {
+	hasNormals := len(m.Normals) == len(m.Faces)
+	flipped := 0
+	for i, flip := range m.orientationFlips() {
+		if flip {
+			f := &m.Faces[i]
+			f[1], f[2] = f[2], f[1]
+			if hasNormals {
+				m.Normals[i] = m.Normals[i].MultScalar(-1)
+			}
+			flipped++
+		}
+	}
+	return flipped
+}
+
+// orientationFlips returns for each face whether FixOrientation flips it.
+func (m *IndexedMesh) orientationFlips() []bool {
+	edgeFaces := make(map[[2]uint32][]faceEdge, 3*len(m.Faces)/2)
+	for i, f := range m.Faces {
+		for e := 0; e < 3; e++ {
+			v, w := f[e], f[(e+1)%3]
+			if v == w {
+				continue
+			}
+			key := undirectedEdge(v, w)
+			edgeFaces[key] = append(edgeFaces[key], faceEdge{face: i, forward: v < w})
+		}
+	}
+
+	flip := make([]bool, len(m.Faces))
+	visited := make([]bool, len(m.Faces))
+	var component, queue []int
+	for start := range m.Faces {
+		if visited[start] {
+			continue
+		}
+
+		// Walk through the shell, orienting every face like the one it
+		// was reached from.
+		component = component[:0]
+		closed := true
+		visited[start] = true
+		queue = append(queue[:0], start)
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			component = append(component, i)
+			f := m.Faces[i]
+			for e := 0; e < 3; e++ {
+				v, w := f[e], f[(e+1)%3]
+				if v == w {
+					continue
+				}
+				uses := edgeFaces[undirectedEdge(v, w)]
+				if len(uses) != 2 {
+					closed = false
+					continue
+				}
+				self, other := uses[0], uses[1]
+				if other.face == i {
+					self, other = other, self
+				}
+				if visited[other.face] {
+					continue
+				}
+				visited[other.face] = true
+				// consistent if the effective directions differ
+				flip[other.face] = other.forward == (self.forward != flip[i])
+				queue = append(queue, other.face)
+			}
+		}
+
+		invert := false
+		if closed {
+			var volume float64
+			for _, i := range component {
+				t := m.Triangle(i)
+				if flip[i] {
+					volume -= t.signedVolume()
+				} else {
+					volume += t.signedVolume()
+				}
+			}
+			invert = volume < 0
+		} else {
+			flipped := 0
+			for _, i := range component {
+				if flip[i] {
+					flipped++
+				}
+			}
+			invert = 2*flipped > len(component)
+		}
+		if invert {
+			for _, i := range component {
+				flip[i] = !flip[i]
+			}
+		}
+	}
+	return flip
+}
@@ -0,0 +1,66 @@
+package stl
+
+// Tests for the repair of triangle orientation.
+
+import (
+	"testing"
+)
+
+func TestFixOrientation(t *testing.T) {
+	s := makeTestSolid()
+	s.Triangles[1].flip()
+	if flipped := s.FixOrientation(); flipped != 1 {
+		t.Errorf("Expected 1 triangle to be flipped, found %d", flipped)
+	}
+	if errors := s.Validate(); len(errors) != 0 {
+		t.Errorf("Expected no errors, found %v", errors)
+	}
+	if s.signedVolume() <= 0 {
+		t.Error("Expected normals to point outside")
+	}
+}
+
+func TestFixOrientation_InsideOut(t *testing.T) {
+	s := makeTorusTestSolid(2, 0.5, 12, 8)
+	s.WeldVertices(0.000001)
+	for i := range s.Triangles {
+		s.Triangles[i].flip()
+	}
+	s.Triangles[5].flip()
+	if flipped := s.FixOrientation(); flipped != len(s.Triangles)-1 {
+		t.Errorf("Expected %d triangles to be flipped, found %d", len(s.Triangles)-1, flipped)
+	}
+	if errors := s.Validate(); len(errors) != 0 {
+		t.Errorf("Expected no errors, found %v", errors)
+	}
+	if s.signedVolume() <= 0 {
+		t.Error("Expected normals to point outside")
+	}
+}
+
+func TestFixOrientation_Open(t *testing.T) {
+	s := makeTestSolid()
+	s.Triangles = s.Triangles[1:]
+	s.Triangles[0].flip()
+	if flipped := s.FixOrientation(); flipped != 1 {
+		t.Errorf("Expected 1 triangle to be flipped, found %d", flipped)
+	}
+	if report := s.Topology(0); !report.IsConsistentlyOriented {
+		t.Error("Expected consistent orientation")
+	}
+}
+
+func TestIndexedMesh_FixOrientation(t *testing.T) {
+	s := makeTestSolid()
+	s.Triangles[1].flip()
+	m := s.ToIndexed(0)
+	if flipped := m.FixOrientation(); flipped != 1 {
+		t.Errorf("Expected 1 face to be flipped, found %d", flipped)
+	}
+	if errors := m.Validate(); len(errors) != 0 {
+		t.Errorf("Expected no errors, found %v", errors)
+	}
+	if !m.ToSolid().sameOrderAlmostEqual(makeTestSolid()) {
+		t.Error("Expected the original faces and normals")
+	}
+}
@@ -0,0 +1,91 @@
+package stl
+
+// This file contains the overhang and support analysis for 3D printing.
+
+import (
+	"math"
+)
+
+// OverhangReport is the result of Solid.OverhangAnalysis.
+type OverhangReport struct {
+	// Triangles are the indices in Solid.Triangles of the overhanging
+	// triangles, in ascending order.
+	Triangles []int
+
+	// OverhangArea is the total area of the overhanging triangles.
+	OverhangArea float64
+
+	// PlateContactArea is the total area of the triangles lying on the
+	// build plate.
+	PlateContactArea float64
+
+	// SupportVolume is the estimated volume of the support structures
+	// below the overhanging triangles.
+	SupportVolume float64
+}
+
+// relativePlateTolerance is multiplied with the height of a solid to get
+// the tolerance for vertices lying on the build plate.
+const relativePlateTolerance = 1e-9
+
+// OverhangAnalysis finds the triangles that need support when the solid is
+// printed with layers stacked in the build direction buildDir. The build
+// plate is the plane orthogonal to buildDir through the lowest vertex.
+// Triangles overhang if their normal points downwards at an angle of more
+// than maxAngle radians below the horizontal plane, which means the surface
+// is inclined by more than maxAngle from the vertical. Triangles lying on the
+// build plate do not overhang.
+//
+// The normals stored in the triangles are used, see RecalculateNormals. The
+// support volume is estimated by projecting each overhanging triangle down
+// from its centroid to the part below it, or to the build plate, using a
+// bounding volume hierarchy.
+func (s *Solid) OverhangAnalysis(buildDir Vec3, maxAngle float64) OverhangReport {
+	var report OverhangReport
+	up := buildDir.UnitVec3()
+	if up == Vec3Zero || len(s.Triangles) == 0 {
+		return report
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := range s.Triangles {
+		for _, v := range s.Triangles[i].Vertices {
+			h := v.Dot(up)
+			lo = math.Min(lo, h)
+			hi = math.Max(hi, h)
+		}
+	}
+	tol := relativePlateTolerance * (hi - lo)
+
+	minDown := math.Sin(maxAngle)
+	down := up.MultScalar(-1)
+	var tree *bvh
+	for i := range s.Triangles {
+		t := &s.Triangles[i]
+		normal := t.Normal.UnitVec3()
+		if normal.Dot(down) <= 0 {
+			continue
+		}
+		area := t.Area()
+		if t.Vertices[0].Dot(up) <= lo+tol && t.Vertices[1].Dot(up) <= lo+tol && t.Vertices[2].Dot(up) <= lo+tol {
+			report.PlateContactArea += area
+			continue
+		}
+		if normal.Dot(down) <= minDown {
+			continue
+		}
+		report.Triangles = append(report.Triangles, i)
+		report.OverhangArea += area
+
+		if tree == nil {
+			tree = newBVH(s.Triangles)
+		}
+		centroid := t.Centroid()
+		height := centroid.Dot(up) - lo
+		if dist, _ := tree.firstHit(Ray{Origin: centroid, Direction: down}, i); dist < height {
+			height = dist
+		}
+		report.SupportVolume += area * normal.Dot(down) * height
+	}
+	return report
+}
@@ -0,0 +1,65 @@
+package stl
+
+// Tests for the overhang and support analysis.
+
+import (
+	"math"
+	"testing"
+)
+
+// makeTowerTestSolid returns a unit cube on the build plate, a unit cube
+// floating 1 above it, and a unit cube floating 1 above the plate next to
+// them.
+func makeTowerTestSolid() *Solid {
+	var s Solid
+	for _, offset := range []Vec3{{0, 0, 0}, {0, 0, 2}, {2, 0, 1}} {
+		box := makeBoxTestSolid(Vec3{1, 1, 1})
+		box.Translate(offset)
+		for _, t := range box.Triangles {
+			s.AppendTriangle(t)
+		}
+	}
+	return &s
+}
+
+func TestOverhangAnalysis(t *testing.T) {
+	s := makeTowerTestSolid()
+	report := s.OverhangAnalysis(Vec3{0, 0, 2}, math.Pi/4)
+	// the bottoms of the floating cubes
+	expected := []int{12, 13, 24, 25}
+	if len(report.Triangles) != len(expected) {
+		t.Fatalf("Expected overhanging triangles %v, found %v", expected, report.Triangles)
+	}
+	for i := range expected {
+		if report.Triangles[i] != expected[i] {
+			t.Fatalf("Expected overhanging triangles %v, found %v", expected, report.Triangles)
+		}
+	}
+	if !almostEqual64(report.OverhangArea, 2, 1e-12) || !almostEqual64(report.PlateContactArea, 1, 1e-12) ||
+		!almostEqual64(report.SupportVolume, 2, 1e-12) {
+		t.Errorf("Expected overhang area 2, plate contact area 1 and support volume 2, found %+v", report)
+	}
+}
+
+func TestOverhangAnalysis_Angle(t *testing.T) {
+	s := makeTowerTestSolid()
+	// tilt the solid by 30 degrees, so the bottoms are inclined by 60 and
+	// the front or back sides by 30 degrees from the vertical
+	s.Rotate(Vec3Zero, Vec3{1, 0, 0}, math.Pi/6)
+	for _, test := range []struct {
+		maxAngle float64
+		count    int
+	}{
+		{maxAngle: math.Pi / 4, count: 6},
+		{maxAngle: math.Pi / 3.1, count: 6},
+		{maxAngle: math.Pi / 6.1, count: 12},
+	} {
+		report := s.OverhangAnalysis(Vec3{0, 0, 1}, test.maxAngle)
+		if len(report.Triangles) != test.count {
+			t.Errorf("Expected %d overhanging triangles for angle %g, found %v", test.count, test.maxAngle, report.Triangles)
+		}
+		if report.PlateContactArea != 0 {
+			t.Errorf("Expected no plate contact for tilted solid, found %g", report.PlateContactArea)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package stl
+
+// This file defines reading operations on io/fs file systems, like
+// embed.FS or zip archives.
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// ReadFS reads the contents of the file name in the file system fsys into a
+// new Solid object. Apart from the file source it works just like ReadFile.
+func ReadFS(fsys fs.FS, name string) (solid *Solid, err error) {
+	var s Solid
+	err = CopyFS(fsys, name, &s)
+	if err == nil {
+		solid = &s
+	}
+	return
+}
+
+// CopyFS reads the file name in the file system fsys and streams its
+// contents into sw. If the opened file supports io.Seeker, it is processed
+// like in CopyAll. Otherwise the format is detected by peeking at the
+// header and comparing the triangle count to the size reported by Stat.
+// Files without a known size, i.e. non-regular files, are read into memory
+// first.
+func CopyFS(fsys fs.FS, name string, sw Writer) (err error) {
+	file, openErr := fsys.Open(name)
+	if openErr != nil {
+		err = openErr
+		return
+	}
+	err = copyFSFile(file, sw)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return
+}
+
+func copyFSFile(file fs.File, sw Writer) (err error) {
+	if rs, isSeeker := file.(io.ReadSeeker); isSeeker {
+		err = CopyAll(rs, sw)
+		return
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+	if !info.Mode().IsRegular() {
+		data, readErr := io.ReadAll(file)
+		if readErr != nil {
+			err = readErr
+			return
+		}
+		err = CopyAll(bytes.NewReader(data), sw)
+		return
+	}
+
+	br := bufio.NewReader(file)
+	isBinary, err := isBinaryStream(br, info.Size())
+	if err != nil {
+		return
+	}
+	err = copyReader(br, isBinary, sw)
+	return
+}
+
+// isBinaryStream works like isBinaryFile, but only peeks at the header of br,
+// so nothing is consumed. size is the total size of the stream in bytes.
+func isBinaryStream(br *bufio.Reader, size int64) (isBinary bool, err error) {
+	header, err := br.Peek(binaryHeaderSize)
+	if err != nil {
+		if err == io.EOF { // too short to meet spec
+			err = nil
+		}
+		return
+	}
+	triangleCount := triangleCountFromBinaryHeader(header)
+	expectedFileLength := int64(triangleCount)*binaryTriangleSize + binaryHeaderSize
+	isBinary = expectedFileLength == size
+	return
+}
@@ -0,0 +1,89 @@
+package stl
+
+// Tests for reading STL files from io/fs file systems.
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+// noSeekFS hides the io.Seeker implementation of the files in fsys, like
+// it is the case for zip archives.
+type noSeekFS struct {
+	fsys fs.FS
+}
+
+type noSeekFile struct {
+	file fs.File
+}
+
+func (f noSeekFS) Open(name string) (fs.File, error) {
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return noSeekFile{file}, nil
+}
+
+func (f noSeekFile) Stat() (fs.FileInfo, error) { return f.file.Stat() }
+func (f noSeekFile) Read(b []byte) (int, error) { return f.file.Read(b) }
+func (f noSeekFile) Close() error               { return f.file.Close() }
+
+func makeTestFS(t *testing.T) fstest.MapFS {
+	fsys := make(fstest.MapFS)
+	for _, name := range []string{testFilenameSimpleASCII, testFilenameSimpleBinary, testFilenameConfusingHeaderBinary} {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	return fsys
+}
+
+func TestReadFS(t *testing.T) {
+	mapFS := makeTestFS(t)
+	fileSystems := map[string]fs.FS{
+		"DirFS":    os.DirFS("."),
+		"MapFS":    mapFS,
+		"noSeekFS": noSeekFS{mapFS},
+	}
+	for fsName, fsys := range fileSystems {
+		for _, fileName := range []string{testFilenameSimpleASCII, testFilenameSimpleBinary} {
+			solid, err := ReadFS(fsys, fileName)
+			if err != nil {
+				t.Errorf("%s %s: %s", fsName, fileName, err)
+				continue
+			}
+			expected, err := ReadFile(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !solid.sameOrderAlmostEqual(expected) {
+				t.Errorf("%s %s: not as expected", fsName, fileName)
+				t.Log("Expected:\n", expected)
+				t.Log("Found:\n", solid)
+			}
+		}
+	}
+}
+
+func TestReadFS_ConfusingHeaderNoSeek(t *testing.T) {
+	solid, err := ReadFS(noSeekFS{makeTestFS(t)}, testFilenameConfusingHeaderBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if solid.IsAscii {
+		t.Error("Expected binary format to be detected")
+	}
+}
+
+func TestReadFS_NotFound(t *testing.T) {
+	_, err := ReadFS(fstest.MapFS{}, "missing.stl")
+	if err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
@@ -6,6 +6,7 @@ package stl
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 )
@@ -16,6 +17,11 @@ var ErrIncompleteBinaryHeader = errors.New("incomplete STL binary header, 84 byt
 // ErrUnexpectedEOF is used by ReadFile and ReadAll to signify an incomplete file.
 var ErrUnexpectedEOF = errors.New("unexpected end of file")
 
+// ErrTooManyTriangles is used when writing a solid with more than
+// MaxBinaryTriangleCount triangles to a binary STL file. Use Solid.SplitForBinary
+// or Solid.WriteFileParts to write such a solid.
+var ErrTooManyTriangles = errors.New("too many triangles for STL binary format")
+
 // ReadFile reads the contents of a file into a new Solid object. The file
 // can be either in STL ASCII format, beginning with "solid ", or in
 // STL binary format, beginning with a 84 byte header. Shorthand for os.Open and ReadAll
@@ -63,8 +69,13 @@ func CopyAll(r io.ReadSeeker, sw Writer) (err error) {
 	if _, err = r.Seek(0, io.SeekStart); err != nil {
 		return
 	}
-	br := bufio.NewReader(r)
+	err = copyReader(bufio.NewReader(r), isBinary, sw)
+	return
+}
 
+// copyReader reads the STL data from br into sw, using the format
+// that has been detected beforehand.
+func copyReader(br *bufio.Reader, isBinary bool, sw Writer) (err error) {
 	if isBinary {
 		sw.SetASCII(false)
 		err = readAllBinary(br, sw)
@@ -72,7 +83,6 @@ func CopyAll(r io.ReadSeeker, sw Writer) (err error) {
 		sw.SetASCII(true)
 		err = readAllASCII(br, sw)
 	}
-
 	return
 }
 
@@ -118,6 +128,26 @@ func (s *Solid) WriteFile(filename string) (err error) {
 	return
 }
 
+// WriteFileParts writes the solid into one or more files, each containing
+// at most MaxBinaryTriangleCount triangles, see SplitForBinary. The file names
+// are generated by fmt.Sprintf(pattern, partNumber), with the part number
+// starting at 1, so pattern should contain a verb like %d or %03d.
+// Returns the names of the files written.
+func (s *Solid) WriteFileParts(pattern string) (filenames []string, err error) {
+	return s.writeFileParts(pattern, maxBinaryTriangles)
+}
+
+func (s *Solid) writeFileParts(pattern string, maxTriangles int) (filenames []string, err error) {
+	for i, part := range s.SplitForBinary(maxTriangles) {
+		filename := fmt.Sprintf(pattern, i+1)
+		if err = part.WriteFile(filename); err != nil {
+			return
+		}
+		filenames = append(filenames, filename)
+	}
+	return
+}
+
 // WriteAll writes the contents of this solid to an io.Writer. Depending on solid.IsAscii
 // the STL ASCII format, or the STL binary format is used. If IsAscii
 // is false, and the binary format is used, solid.Name will be used for
@@ -214,6 +214,41 @@ func TestWriteFile_Binary(t *testing.T) {
 	}
 }
 
+func TestWriteFileParts(t *testing.T) {
+	tmpDirName, tmpErr := ioutil.TempDir(os.TempDir(), "stl_test")
+	if tmpErr != nil {
+		t.Fatal(tmpErr.Error())
+	}
+	defer os.RemoveAll(tmpDirName)
+
+	testSolid := makeTestSolid()
+	testSolid.IsAscii = false
+	pattern := tmpDirName + string(os.PathSeparator) + "test_out_part%d.stl"
+	filenames, err := testSolid.writeFileParts(pattern, 3)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(filenames) != 2 {
+		t.Fatalf("Expected 2 files, found %v", filenames)
+	}
+
+	var readBack Solid
+	for _, filename := range filenames {
+		part, err := ReadFile(filename)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		readBack.Name = part.Name
+		readBack.Triangles = append(readBack.Triangles, part.Triangles...)
+	}
+	testSolid.BinaryHeader = nil
+	if !readBack.sameOrderAlmostEqual(testSolid) {
+		t.Error("Parts do not add up to the original solid")
+		t.Log("Expected:\n", testSolid)
+		t.Log("Found:\n", readBack)
+	}
+}
+
 func BenchmarkWriteSmallFile_Binary(b *testing.B) {
 	tmpDirName, tmpErr := ioutil.TempDir(os.TempDir(), "stl_test")
 	if tmpErr != nil {
@@ -0,0 +1,157 @@
+package stl
+
+// This file contains a pipeline combining the repair functions.
+
+// RepairOptions selects the steps run by Solid.Repair and IndexedMesh.Repair.
+// The steps are always run in the order of the fields.
+type RepairOptions struct {
+	// Weld welds vertices closer to each other than WeldTolerance,
+	// see Solid.WeldVertices.
+	Weld          bool
+	WeldTolerance float64
+
+	// RemoveDegenerates removes triangles with an area not greater than
+	// DegenerateAreaTolerance, see Solid.RemoveDegenerates.
+	RemoveDegenerates       bool
+	DegenerateAreaTolerance float64
+
+	// RemoveDuplicates removes duplicate triangles, see Solid.RemoveDuplicates.
+	RemoveDuplicates bool
+
+	// FixOrientation makes the orientation of the triangles consistent,
+	// see Solid.FixOrientation.
+	FixOrientation bool
+
+	// FillHoles closes holes using FillHolesOptions, see Solid.FillHoles.
+	FillHoles        bool
+	FillHolesOptions FillHolesOptions
+
+	// RecalculateNormals recalculates all normals from the vertices,
+	// see Solid.RecalculateNormals.
+	RecalculateNormals bool
+}
+
+// DefaultRepairOptions returns options running all steps, welding vertices
+// closer than 1e-6 to each other, and filling holes using FillMinimumArea.
+func DefaultRepairOptions() RepairOptions {
+	return RepairOptions{
+		Weld:               true,
+		WeldTolerance:      1e-6,
+		RemoveDegenerates:  true,
+		RemoveDuplicates:   true,
+		FixOrientation:     true,
+		FillHoles:          true,
+		FillHolesOptions:   FillHolesOptions{Method: FillMinimumArea},
+		RecalculateNormals: true,
+	}
+}
+
+// RepairReport contains the changes made by Solid.Repair or IndexedMesh.Repair.
+type RepairReport struct {
+	// Number of triangles before and after the repair
+	TrianglesBefore, TrianglesAfter int
+
+	// VerticesWelded is the number of vertices moved by welding.
+	VerticesWelded int
+
+	// DegeneratesRemoved is the number of degenerate triangles removed.
+	DegeneratesRemoved int
+
+	// DuplicatesRemoved is the number of duplicate triangles removed.
+	DuplicatesRemoved int
+
+	// TrianglesFlipped is the number of triangles whose orientation was fixed.
+	TrianglesFlipped int
+
+	// HolesFilled is the number of holes closed.
+	HolesFilled int
+
+	// NormalsFixed is the number of normals that did not match their
+	// triangle before they were recalculated, as reported by Validate.
+	NormalsFixed int
+}
+
+// Changed is true if the repair modified the solid.
+func (r *RepairReport) Changed() bool {
+	return r.VerticesWelded != 0 || r.DegeneratesRemoved != 0 ||
+		r.DuplicatesRemoved != 0 || r.TrianglesFlipped != 0 ||
+		r.HolesFilled != 0 || r.NormalsFixed != 0
+}
+
+// Repair runs the steps selected by opts and reports what they changed.
+func (s *Solid) Repair(opts RepairOptions) RepairReport {
+	report := RepairReport{TrianglesBefore: len(s.Triangles)}
+	repair(s, opts, &report)
+	report.TrianglesAfter = len(s.Triangles)
+	return report
+}
+
+// Repair runs the steps selected by opts on the mesh, like Solid.Repair.
+// Welding merges vertices, so VerticesWelded is the number of vertices
+// removed from m.Vertices, see IndexedMesh.WeldVertices.
+func (m *IndexedMesh) Repair(opts RepairOptions) RepairReport {
+	report := RepairReport{TrianglesBefore: len(m.Faces)}
+	repair(m, opts, &report)
+	report.TrianglesAfter = len(m.Faces)
+	return report
+}
+
+// repairable is implemented by Solid and IndexedMesh to run the steps of
+// a repair.
+type repairable interface {
+	WeldVertices(tol float64) int
+	RemoveDegenerates(areaTol float64) int
+	RemoveDuplicates() int
+	FixOrientation() int
+	FillHoles(opts FillHolesOptions) int
+
+	// fixNormals recalculates the normals, returning the number of
+	// them that did not match their triangle before.
+	fixNormals() int
+}
+
+// repair runs the steps selected by opts on r, recording the changes in report.
+func repair(r repairable, opts RepairOptions, report *RepairReport) {
+	if opts.Weld {
+		report.VerticesWelded = r.WeldVertices(opts.WeldTolerance)
+	}
+	if opts.RemoveDegenerates {
+		report.DegeneratesRemoved = r.RemoveDegenerates(opts.DegenerateAreaTolerance)
+	}
+	if opts.RemoveDuplicates {
+		report.DuplicatesRemoved = r.RemoveDuplicates()
+	}
+	if opts.FixOrientation {
+		report.TrianglesFlipped = r.FixOrientation()
+	}
+	if opts.FillHoles {
+		report.HolesFilled = r.FillHoles(opts.FillHolesOptions)
+	}
+	if opts.RecalculateNormals {
+		report.NormalsFixed = r.fixNormals()
+	}
+}
+
+// fixNormals implements repairable.
+func (s *Solid) fixNormals() int {
+	fixed := 0
+	for i := range s.Triangles {
+		if !s.Triangles[i].checkNormal(normalAngleTolerance) {
+			fixed++
+		}
+	}
+	s.RecalculateNormals()
+	return fixed
+}
+
+// fixNormals implements repairable. Missing normals are not counted.
+func (m *IndexedMesh) fixNormals() int {
+	fixed := 0
+	for i := range m.Faces {
+		if t := m.Triangle(i); !t.checkNormal(normalAngleTolerance) {
+			fixed++
+		}
+	}
+	m.RecalculateNormals()
+	return fixed
+}
@@ -0,0 +1,71 @@
+package stl
+
+// Tests for the repair pipeline.
+
+import (
+	"testing"
+)
+
+// makeRepairTestSolid returns the test solid with one problem for every
+// repair step.
+func makeRepairTestSolid() *Solid {
+	s := makeTestSolid()
+	s.Triangles = s.Triangles[1:]                            // hole
+	s.Triangles[0].flip()                                    // wrong orientation
+	s.Triangles[1].Vertices[0][0] += 0.0000001               // rounding error
+	s.Triangles[2].Normal = Vec3{0, 0, 1}                    // wrong normal
+	s.AppendTriangle(s.Triangles[2])                         // duplicate
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}}}) // degenerate
+	return s
+}
+
+// expectedRepairReport is the report of repairing makeRepairTestSolid.
+var expectedRepairReport = RepairReport{
+	TrianglesBefore:    5,
+	TrianglesAfter:     4,
+	VerticesWelded:     1,
+	DegeneratesRemoved: 1,
+	DuplicatesRemoved:  1,
+	TrianglesFlipped:   1,
+	HolesFilled:        1,
+	NormalsFixed:       1,
+}
+
+func TestRepair(t *testing.T) {
+	s := makeRepairTestSolid()
+	if report := s.Repair(DefaultRepairOptions()); report != expectedRepairReport {
+		t.Errorf("Expected %+v, found %+v", expectedRepairReport, report)
+	}
+	if errors := s.Validate(); len(errors) != 0 {
+		t.Errorf("Expected valid solid, found %v", errors)
+	}
+	if topology := s.Topology(0); !topology.IsClosed || !topology.IsConsistentlyOriented {
+		t.Errorf("Expected closed and oriented solid, found %+v", topology)
+	}
+
+	if report := s.Repair(DefaultRepairOptions()); report.Changed() {
+		t.Errorf("Expected valid solid to be unchanged, found %+v", report)
+	}
+	if report := s.Repair(RepairOptions{}); report.Changed() || report.TrianglesAfter != 4 {
+		t.Errorf("Expected no steps to run, found %+v", report)
+	}
+}
+
+func TestIndexedMesh_Repair(t *testing.T) {
+	m := makeRepairTestSolid().ToIndexed(0)
+	if report := m.Repair(DefaultRepairOptions()); report != expectedRepairReport {
+		t.Errorf("Expected %+v, found %+v", expectedRepairReport, report)
+	}
+	if errors := m.Validate(); len(errors) != 0 {
+		t.Errorf("Expected valid mesh, found %v", errors)
+	}
+	if topology := m.Topology(); !topology.IsClosed || !topology.IsConsistentlyOriented {
+		t.Errorf("Expected closed and oriented mesh, found %+v", topology)
+	}
+	if !m.ToSolid().Equal(makeTestSolid(), 0) {
+		t.Error("Expected the test solid after the repair")
+	}
+	if report := m.Repair(DefaultRepairOptions()); report.Changed() {
+		t.Errorf("Expected valid mesh to be unchanged, found %+v", report)
+	}
+}
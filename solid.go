@@ -59,6 +59,32 @@ func (s *Solid) AppendTriangle(t Triangle) {
 	s.Triangles = append(s.Triangles, t)
 }
 
+// SplitForBinary splits the solid into parts of at most maxTriangles triangles
+// each, so every part can be written into a binary STL file. If maxTriangles
+// is <= 0 or greater than MaxBinaryTriangleCount, MaxBinaryTriangleCount is used.
+// The parts share Name, BinaryHeader, and IsAscii with s, and their Triangles
+// slices share the underlying array with s.Triangles. At least one part is
+// returned, even for an empty solid.
+func (s *Solid) SplitForBinary(maxTriangles int) []*Solid {
+	if maxTriangles <= 0 || uint64(maxTriangles) > MaxBinaryTriangleCount {
+		maxTriangles = maxBinaryTriangles
+	}
+	parts := make([]*Solid, 0, len(s.Triangles)/maxTriangles+1)
+	for start := 0; start < len(s.Triangles) || start == 0; start += maxTriangles {
+		end := len(s.Triangles)
+		if end-start > maxTriangles {
+			end = start + maxTriangles
+		}
+		parts = append(parts, &Solid{
+			BinaryHeader: s.BinaryHeader,
+			Name:         s.Name,
+			Triangles:    s.Triangles[start:end:end],
+			IsAscii:      s.IsAscii,
+		})
+	}
+	return parts
+}
+
 // SolidMeasure is used to store the result of Solid.Measure()
 type SolidMeasure struct {
 	// Minimum values for axes
@@ -244,6 +270,19 @@ type TriangleErrors struct {
 	// a line, or even a point, as opposed to a triangle.
 	HasEqualVertices bool
 
+	// IsDegenerate is true if the triangle has no area, because its vertices
+	// are collinear. Solid.ValidateWithTolerance also reports triangles whose
+	// height is within the tolerance.
+	IsDegenerate bool
+
+	// DuplicateTriangles are indexes in Solid.Triangles of triangles with the
+	// same vertices in the same orientation.
+	DuplicateTriangles []int
+
+	// FlippedDuplicateTriangles are indexes in Solid.Triangles of triangles
+	// with the same vertices in the opposite orientation.
+	FlippedDuplicateTriangles []int
+
 	// NormalDoesNotMatch istrue if the normal vector does not match a normal calculated from the
 	// vertices in the right hand order, even allowing for an angular difference
 	// of < 90 degree.
@@ -255,6 +294,10 @@ type TriangleErrors struct {
 	//    2: V2 -> V0
 	// If the edge has no error its value is nil.
 	EdgeErrors [3]*EdgeError
+
+	// VertexErrors by vertex index within the triangle. If the vertex has
+	// no error its value is nil.
+	VertexErrors [3]*VertexError
 }
 
 // edge is a convenience accessor that allocates an EdgeError for edge e if
@@ -267,6 +310,15 @@ func (te *TriangleErrors) edge(e int) *EdgeError {
 	return te.EdgeErrors[e]
 }
 
+// vertex is a convenience accessor that allocates a VertexError for vertex v
+// if it is not already present.
+func (te *TriangleErrors) vertex(v int) *VertexError {
+	if te.VertexErrors[v] == nil {
+		te.VertexErrors[v] = new(VertexError)
+	}
+	return te.VertexErrors[v]
+}
+
 // EdgeError describes the errors found for a single edge within a triangle using
 // Solid.Validate().
 type EdgeError struct {
@@ -298,44 +350,20 @@ func (eer *EdgeError) HasNoCounterEdge() bool {
 	return len(eer.CounterEdgeTriangles) == 0
 }
 
-// For every edge described by two points this data structure stores
-// the set of indices of triangles containing this edge.
-type edgeLookup struct {
-	edgeToTriangles map[[2]Vec3]map[int]bool
-}
-
-func newEdgeLookup() *edgeLookup {
-	var l edgeLookup
-	l.edgeToTriangles = make(map[[2]Vec3]map[int]bool)
-	return &l
-}
-
-// Get the indices to Solid.Triangles of triangles containing
-// the edge v -> w, excluding a specific one denoted by i.
-func (l *edgeLookup) OtherTrianglesWithEdge(v, w Vec3, i int) []int {
-	triangleSet, found := l.edgeToTriangles[[2]Vec3{v, w}]
-	if !found {
-		return nil
-	}
-	r := make([]int, 0, len(triangleSet))
-	for t := range triangleSet {
-		if t != i {
-			r = append(r, t)
-		}
-	}
-	return r
+// VertexError describes the errors found for a single vertex within a triangle
+// using Solid.Validate().
+type VertexError struct {
+	// OtherFanTriangles are indexes in Solid.Triangles of triangles that contain
+	// the vertex, but are not connected to this triangle by a chain of triangles
+	// sharing edges around the vertex. This is the case e.g. for two cones
+	// touching in their tips.
+	OtherFanTriangles []int
 }
 
-// Put triangleIndex into the set of indices of triangles containing
-// the edge v -> w.
-func (l *edgeLookup) InsertEdge(triangleIndex int, v, w Vec3) {
-	key := [2]Vec3{v, w}
-	triangleSet, found := l.edgeToTriangles[key]
-	if !found {
-		l.edgeToTriangles[key] = make(map[int]bool)
-		triangleSet = l.edgeToTriangles[key]
-	}
-	triangleSet[triangleIndex] = true
+// IsNonManifold is true if the triangles around the vertex form more than one
+// fan, i.e. the surface touches itself in the vertex.
+func (ver *VertexError) IsNonManifold() bool {
+	return len(ver.OtherFanTriangles) != 0
 }
 
 // triangleErrorsMap represents errors by triangle index
@@ -351,61 +379,37 @@ func (m triangleErrorsMap) item(triangleIdx int) *TriangleErrors {
 
 const normalAngleTolerance = HalfPi
 
-// Validate looks for triangles that are really lines or dots, and for edges that
-// violate the vertex-to-vertex rule. Returns a map of errors by triangle
-// index that could be used to print out an error report.
+// Validate looks for triangles that are really lines or dots, for duplicate
+// triangles, for edges that violate the vertex-to-vertex rule, and for
+// non-manifold vertices. Returns a map of errors by triangle
+// index that could be used to print out an error report. Vertices are
+// compared exactly, see ValidateWithTolerance.
 func (s *Solid) Validate() map[int]*TriangleErrors {
-	// Build up lookup from edge to triangle
-	e := newEdgeLookup()
-	for i := range s.Triangles {
-		t := &s.Triangles[i]
-		for vertex1 := 0; vertex1 < 3; vertex1++ {
-			vertex2 := (vertex1 + 1) % 3
-			e.InsertEdge(i, t.Vertices[vertex1], t.Vertices[vertex2])
-		}
-	}
-
-	// Now look for every edge that there is exactly one
-	// "counter-edge" in the opposite direction for another triangle,
-	// and that the same edge is not used by another triangle.
-	triangleErrors := make(triangleErrorsMap)
-
-	for i := range s.Triangles {
-		t := &s.Triangles[i]
-		// check for equal vertices
-		if t.hasEqualVertices() {
-			triangleErrors.item(i).HasEqualVertices = true
-		}
-
-		// check if normal matches vertices
-		if !t.checkNormal(normalAngleTolerance) {
-			triangleErrors.item(i).NormalDoesNotMatch = true
-		}
+	return s.ValidateWithTolerance(0)
+}
 
-		// loop through edges, vertex1 is also the index of the edge
-		for vertex1 := 0; vertex1 < 3; vertex1++ {
-			vertex2 := (vertex1 + 1) % 3
+// ValidateWithTolerance works like Validate, but treats vertices closer
+// to each other than tol as equal, like WeldVertices does. This avoids false
+// errors in files written with rounding differences.
+func (s *Solid) ValidateWithTolerance(tol float64) map[int]*TriangleErrors {
+	return s.ValidateWithOptions(ValidateOptions{Tolerance: tol})
+}
 
-			// look for other triangles with same edge
-			sameEdgeTriangles := e.OtherTrianglesWithEdge(
-				t.Vertices[vertex1], t.Vertices[vertex2], i)
-			if len(sameEdgeTriangles) > 0 {
-				// used by other triangles
-				triangleErrors.item(i).edge(vertex1).SameEdgeTriangles = sameEdgeTriangles
-			}
+// ValidateOptions configures Solid.ValidateWithOptions.
+type ValidateOptions struct {
+	// Tolerance is the distance within which vertices are treated as
+	// equal, see ValidateWithTolerance.
+	Tolerance float64
 
-			// Look for other triangles with edge in opposite direction.
-			// If this same triangle had the edge in the opposite direction, we
-			// would already have the HasEqualVertices error, so we do not need
-			// to take care of this here.
-			counterEdgeTriangles := e.OtherTrianglesWithEdge(
-				t.Vertices[vertex2], t.Vertices[vertex1], i)
-			if len(counterEdgeTriangles) != 1 {
-				// 0 or multiple "counter-edges"
-				triangleErrors.item(i).edge(vertex1).CounterEdgeTriangles = counterEdgeTriangles
-			}
-		}
-	}
+	// Workers is the number of goroutines checking the triangles in parallel.
+	// With 0 or 1 they are checked sequentially. Use runtime.GOMAXPROCS(0)
+	// for large solids.
+	Workers int
+}
 
-	return triangleErrors
+// ValidateWithOptions works like ValidateWithTolerance, and optionally
+// checks the triangles in parallel. The result does not depend on the
+// number of workers.
+func (s *Solid) ValidateWithOptions(opts ValidateOptions) map[int]*TriangleErrors {
+	return s.ToIndexed(opts.Tolerance).validate(opts.Tolerance, opts.Workers)
 }
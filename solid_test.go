@@ -126,6 +126,45 @@ func TestMeasure(t *testing.T) {
 	}
 }
 
+func TestSplitForBinary(t *testing.T) {
+	s := makeTestSolid()
+	cases := []struct {
+		maxTriangles  int
+		expectedSizes []int
+	}{
+		{0, []int{4}},
+		{1, []int{1, 1, 1, 1}},
+		{3, []int{3, 1}},
+		{4, []int{4}},
+		{5, []int{4}},
+	}
+	for _, tc := range cases {
+		parts := s.SplitForBinary(tc.maxTriangles)
+		if len(parts) != len(tc.expectedSizes) {
+			t.Errorf("maxTriangles %d: expected %d parts, found %d", tc.maxTriangles, len(tc.expectedSizes), len(parts))
+			continue
+		}
+		i := 0
+		for p, part := range parts {
+			if len(part.Triangles) != tc.expectedSizes[p] {
+				t.Errorf("maxTriangles %d: expected %d triangles in part %d, found %d",
+					tc.maxTriangles, tc.expectedSizes[p], p, len(part.Triangles))
+			}
+			for _, triangle := range part.Triangles {
+				if triangle != s.Triangles[i] {
+					t.Errorf("maxTriangles %d: triangle %d not as expected", tc.maxTriangles, i)
+				}
+				i++
+			}
+		}
+	}
+
+	var empty Solid
+	if parts := empty.SplitForBinary(10); len(parts) != 1 || len(parts[0].Triangles) != 0 {
+		t.Errorf("Expected one empty part for empty solid, found %v", parts)
+	}
+}
+
 func TestRotate(t *testing.T) {
 	sOrig := makeTestSolid()
 	s := makeTestSolid()
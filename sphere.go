@@ -0,0 +1,149 @@
+package stl
+
+// This file contains the calculation of bounding spheres.
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Sphere is a sphere given by its center and radius.
+type Sphere struct {
+	Center Vec3
+	Radius float64
+}
+
+// Contains is true if p is inside the sphere or closer than tol to its surface.
+func (sp *Sphere) Contains(p Vec3, tol float64) bool {
+	return p.Diff(sp.Center).Len() <= sp.Radius+tol
+}
+
+// relativeSphereTolerance is multiplied with the radius of a sphere to get
+// the tolerance for points on its surface during its construction.
+const relativeSphereTolerance = 1e-12
+
+// BoundingSphere returns the smallest sphere containing all vertices of the
+// solid, using Welzl's algorithm in expected linear time. The vertices are
+// processed in a pseudo-random order, so the result is reproducible.
+func (s *Solid) BoundingSphere() Sphere {
+	points := s.uniqueVertices()
+	if len(points) == 0 {
+		return Sphere{}
+	}
+	r := rand.New(rand.NewSource(1))
+	r.Shuffle(len(points), func(i, j int) {
+		points[i], points[j] = points[j], points[i]
+	})
+	return welzl(points, len(points), nil)
+}
+
+// BoundingSphereApprox returns a sphere containing all vertices of the solid,
+// using Ritter's algorithm. It is faster than BoundingSphere, but the radius
+// is usually a few percent larger than the minimum.
+func (s *Solid) BoundingSphereApprox() Sphere {
+	if len(s.Triangles) == 0 {
+		return Sphere{}
+	}
+	// start with the sphere around a pair of distant points
+	farthest := func(from Vec3) Vec3 {
+		best, bestDist := from, -1.0
+		for i := range s.Triangles {
+			for _, v := range s.Triangles[i].Vertices {
+				if d := v.Diff(from).Len(); d > bestDist {
+					best, bestDist = v, d
+				}
+			}
+		}
+		return best
+	}
+	x := farthest(s.Triangles[0].Vertices[0])
+	y := farthest(x)
+	sphere := Sphere{Center: x.Add(y).MultScalar(0.5), Radius: y.Diff(x).Len() / 2}
+
+	// grow the sphere to include points outside
+	for i := range s.Triangles {
+		for _, v := range s.Triangles[i].Vertices {
+			d := v.Diff(sphere.Center).Len()
+			if d > sphere.Radius {
+				radius := (sphere.Radius + d) / 2
+				sphere.Center = sphere.Center.Add(v.Diff(sphere.Center).MultScalar((radius - sphere.Radius) / d))
+				sphere.Radius = radius
+			}
+		}
+	}
+	return sphere
+}
+
+// welzl returns the smallest sphere containing points[:n], with the points
+// of support on its surface.
+func welzl(points []Vec3, n int, support []Vec3) Sphere {
+	sphere := sphereThrough(support)
+	if len(support) == 4 {
+		return sphere
+	}
+	for i := 0; i < n; i++ {
+		if !sphere.Contains(points[i], relativeSphereTolerance*sphere.Radius) {
+			sphere = welzl(points, i, append(support[:len(support):len(support)], points[i]))
+		}
+	}
+	return sphere
+}
+
+// sphereThrough returns the smallest sphere with up to four points on its
+// surface. For degenerate configurations, like collinear points, the smallest
+// sphere containing all of them is returned instead. Without points, the
+// radius is negative.
+func sphereThrough(points []Vec3) Sphere {
+	switch len(points) {
+	case 0:
+		return Sphere{Radius: -1}
+	case 1:
+		return Sphere{Center: points[0]}
+	case 2:
+		return Sphere{
+			Center: points[0].Add(points[1]).MultScalar(0.5),
+			Radius: points[1].Diff(points[0]).Len() / 2,
+		}
+	case 3:
+		a, b, c := points[0], points[1], points[2]
+		ab, ac := b.Diff(a), c.Diff(a)
+		n := ab.Cross(ac)
+		n2 := n.Dot(n)
+		if n2 <= epsilon64*ab.Dot(ab)*ac.Dot(ac) {
+			return smallestSphereOfSubsets(points)
+		}
+		offset := n.Cross(ab).MultScalar(ac.Dot(ac)).Add(ac.Cross(n).MultScalar(ab.Dot(ab))).MultScalar(1 / (2 * n2))
+		return Sphere{Center: a.Add(offset), Radius: offset.Len()}
+	}
+	a := points[0]
+	ab, ac, ad := points[1].Diff(a), points[2].Diff(a), points[3].Diff(a)
+	det := ab.Dot(ac.Cross(ad))
+	if math.Abs(det) <= epsilon64*ab.Len()*ac.Len()*ad.Len() {
+		return smallestSphereOfSubsets(points)
+	}
+	offset := ac.Cross(ad).MultScalar(ab.Dot(ab)).
+		Add(ad.Cross(ab).MultScalar(ac.Dot(ac))).
+		Add(ab.Cross(ac).MultScalar(ad.Dot(ad))).
+		MultScalar(1 / (2 * det))
+	return Sphere{Center: a.Add(offset), Radius: offset.Len()}
+}
+
+// smallestSphereOfSubsets returns the smallest sphere through all but one
+// of points that contains all of them.
+func smallestSphereOfSubsets(points []Vec3) Sphere {
+	best := Sphere{Radius: math.Inf(1)}
+	subset := make([]Vec3, 0, len(points)-1)
+	for skip := range points {
+		subset = subset[:0]
+		for i, p := range points {
+			if i != skip {
+				subset = append(subset, p)
+			}
+		}
+		sphere := sphereThrough(subset)
+		if sphere.Radius < best.Radius && sphere.Contains(points[skip], relativeSphereTolerance*sphere.Radius) {
+			best = sphere
+		}
+	}
+	return best
+}
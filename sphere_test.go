@@ -0,0 +1,89 @@
+package stl
+
+// Tests for the calculation of bounding spheres.
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBoundingSphere_Box(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{1, 2, 2})
+	s.Translate(Vec3{1, 1, 1})
+	sphere := s.BoundingSphere()
+	if !sphere.Center.AlmostEqual(Vec3{1.5, 2, 2}, 1e-12) || !almostEqual64(sphere.Radius, 1.5, 1e-12) {
+		t.Errorf("Expected sphere around [1.5 2 2] with radius 1.5, found %+v", sphere)
+	}
+	approx := s.BoundingSphereApprox()
+	if approx.Radius < sphere.Radius-1e-12 {
+		t.Errorf("Expected approximate radius of at least %v, found %v", sphere.Radius, approx.Radius)
+	}
+	for _, tri := range s.Triangles {
+		for _, v := range tri.Vertices {
+			if !approx.Contains(v, 1e-9) {
+				t.Errorf("Expected %v in approximate sphere %+v", v, approx)
+			}
+		}
+	}
+}
+
+func TestBoundingSphere_Torus(t *testing.T) {
+	s := makeTorusTestSolid(2, 0.5, 24, 12)
+	s.Rotate(Vec3Zero, Vec3{1, 2, 3}.UnitVec3(), 1)
+	sphere := s.BoundingSphere()
+	if !sphere.Center.AlmostEqual(Vec3Zero, 1e-9) || !almostEqual64(sphere.Radius, 2.5, 1e-9) {
+		t.Errorf("Expected sphere around origin with radius 2.5, found %+v", sphere)
+	}
+	if sphere := (&Solid{}).BoundingSphere(); sphere != (Sphere{}) {
+		t.Errorf("Expected empty sphere, found %+v", sphere)
+	}
+}
+
+func TestBoundingSphere_Minimal(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for it := 0; it < 50; it++ {
+		var s Solid
+		points := make([]Vec3, 0, 12)
+		for i := 0; i < 4; i++ {
+			var tri Triangle
+			for v := range tri.Vertices {
+				tri.Vertices[v] = Vec3{r.Float64(), r.Float64(), r.Float64()}
+				points = append(points, tri.Vertices[v])
+			}
+			s.AppendTriangle(tri)
+		}
+		sphere := s.BoundingSphere()
+
+		// brute force over all spheres through up to four points
+		best := math.Inf(1)
+		var subsets func(start int, chosen []Vec3)
+		subsets = func(start int, chosen []Vec3) {
+			if len(chosen) > 0 {
+				candidate := sphereThrough(chosen)
+				containsAll := true
+				for _, p := range points {
+					containsAll = containsAll && candidate.Contains(p, 1e-9)
+				}
+				if containsAll {
+					best = math.Min(best, candidate.Radius)
+				}
+			}
+			if len(chosen) == 4 {
+				return
+			}
+			for i := start; i < len(points); i++ {
+				subsets(i+1, append(chosen[:len(chosen):len(chosen)], points[i]))
+			}
+		}
+		subsets(0, nil)
+		if !almostEqual64(sphere.Radius, best, 1e-9) {
+			t.Errorf("Expected radius %v, found %v", best, sphere.Radius)
+		}
+		for _, p := range points {
+			if !sphere.Contains(p, 1e-9) {
+				t.Errorf("Expected %v in sphere %+v", p, sphere)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package stl
+
+// This file contains statistics on the triangles of a solid, used to judge
+// the quality of a mesh.
+
+import (
+	"math"
+)
+
+// StatisticsHistogramBins is the number of bins of a Histogram in
+// MeshStatistics.
+const StatisticsHistogramBins = 20
+
+// Histogram describes the distribution of a set of values.
+type Histogram struct {
+	// Count is the number of values.
+	Count int
+
+	// Min, Max and Mean of the values
+	Min, Max, Mean float64
+
+	// Bins counts the values in StatisticsHistogramBins bins of equal width
+	// BinWidth, starting at Min. Values equal to Max are counted in the
+	// last bin.
+	Bins     []int
+	BinWidth float64
+}
+
+// newHistogram returns the histogram of values.
+func newHistogram(values []float64) Histogram {
+	h := Histogram{Count: len(values), Bins: make([]int, StatisticsHistogramBins)}
+	if len(values) == 0 {
+		return h
+	}
+	h.Min, h.Max = math.Inf(1), math.Inf(-1)
+	var sum float64
+	for _, v := range values {
+		h.Min = math.Min(h.Min, v)
+		h.Max = math.Max(h.Max, v)
+		sum += v
+	}
+	h.Mean = sum / float64(len(values))
+	h.BinWidth = (h.Max - h.Min) / StatisticsHistogramBins
+	for _, v := range values {
+		bin := 0
+		if h.BinWidth > 0 {
+			bin = int((v - h.Min) / h.BinWidth)
+			if bin >= StatisticsHistogramBins {
+				bin = StatisticsHistogramBins - 1
+			}
+		}
+		h.Bins[bin]++
+	}
+	return h
+}
+
+// MeshStatistics is the result of Solid.Statistics.
+type MeshStatistics struct {
+	// TriangleCount is the number of triangles, and DegenerateCount the
+	// number of them ValidateWithTolerance reports as IsDegenerate with the
+	// same tolerance, see Solid.RemoveDegeneratesWithTolerance.
+	TriangleCount, DegenerateCount int
+
+	// EdgeLength is the distribution of the lengths of the edges. Edges
+	// shared by several triangles are counted once.
+	EdgeLength Histogram
+
+	// Area is the distribution of the areas of the triangles.
+	Area Histogram
+
+	// AspectRatio is the distribution of Triangle.AspectRatio over the
+	// triangles that are not degenerate.
+	AspectRatio Histogram
+
+	// MinAngle is the smallest Triangle.MinAngle of all triangles in
+	// radians.
+	MinAngle float64
+}
+
+// Statistics returns the distributions of edge length, area and aspect ratio
+// of the triangles. Vertices are compared exactly to find shared edges, see
+// StatisticsWithTolerance.
+func (s *Solid) Statistics() MeshStatistics {
+	return s.StatisticsWithTolerance(0)
+}
+
+// StatisticsWithTolerance works like Statistics, but treats vertices closer
+// to each other than tol as equal, and triangles with a height over their
+// longest edge not greater than tol as degenerate, like ValidateWithTolerance
+// does.
+func (s *Solid) StatisticsWithTolerance(tol float64) MeshStatistics {
+	stats := MeshStatistics{TriangleCount: len(s.Triangles), MinAngle: math.Pi}
+	if len(s.Triangles) == 0 {
+		stats.MinAngle = 0
+	}
+
+	areas := make([]float64, len(s.Triangles))
+	aspectRatios := make([]float64, 0, len(s.Triangles))
+	for i := range s.Triangles {
+		t := &s.Triangles[i]
+		areas[i] = t.Area()
+		if t.hasEqualVertices() || t.isCollinear(tol) {
+			stats.DegenerateCount++
+		} else {
+			aspectRatios = append(aspectRatios, t.AspectRatio())
+		}
+		stats.MinAngle = math.Min(stats.MinAngle, t.MinAngle())
+	}
+
+	m := s.ToIndexed(tol)
+	seen := make(map[[2]uint32]bool, 3*len(m.Faces)/2)
+	lengths := make([]float64, 0, 3*len(m.Faces)/2)
+	for _, f := range m.Faces {
+		for e := 0; e < 3; e++ {
+			key := undirectedEdge(f[e], f[(e+1)%3])
+			if !seen[key] {
+				seen[key] = true
+				lengths = append(lengths, m.Vertices[key[1]].Diff(m.Vertices[key[0]]).Len())
+			}
+		}
+	}
+
+	stats.EdgeLength = newHistogram(lengths)
+	stats.Area = newHistogram(areas)
+	stats.AspectRatio = newHistogram(aspectRatios)
+	return stats
+}
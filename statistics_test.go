@@ -0,0 +1,64 @@
+package stl
+
+// Tests for the mesh statistics.
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStatistics(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{1, 1, 1})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}}})
+	stats := s.Statistics()
+	if stats.TriangleCount != 13 || stats.DegenerateCount != 1 {
+		t.Errorf("Expected 13 triangles with 1 degenerate, found %+v", stats)
+	}
+	if stats.MinAngle != 0 {
+		t.Errorf("Expected minimum angle 0, found %g", stats.MinAngle)
+	}
+
+	// 12 box edges of length 1, 6 diagonals, and the 2 new edges
+	edges := stats.EdgeLength
+	if edges.Count != 20 || edges.Min != 1 || edges.Max != 2 {
+		t.Errorf("Expected 20 edges from 1 to 2, found %+v", edges)
+	}
+	if edges.Bins[0] != 13 || edges.Bins[StatisticsHistogramBins-1] != 1 || !almostEqual64(edges.BinWidth, 0.05, 1e-12) {
+		t.Errorf("Expected 13 edges in the first and 1 in the last bin of width 0.05, found %+v", edges)
+	}
+	diagonal := int((math.Sqrt2 - 1) / edges.BinWidth)
+	if edges.Bins[diagonal] != 6 {
+		t.Errorf("Expected 6 diagonals in bin %d, found %v", diagonal, edges.Bins)
+	}
+
+	if area := stats.Area; area.Count != 13 || area.Min != 0 || area.Max != 0.5 || area.Bins[0] != 1 || area.Bins[StatisticsHistogramBins-1] != 12 {
+		t.Errorf("Expected 1 degenerate and 12 triangles of area 0.5, found %+v", area)
+	}
+
+	// right isosceles triangles: circumradius sqrt(2)/2, inradius 1-sqrt(2)/2
+	expected := math.Sqrt2 / 2 / (2 - math.Sqrt2)
+	ratio := stats.AspectRatio
+	if ratio.Count != 12 || !almostEqual64(ratio.Min, expected, 1e-12) || !almostEqual64(ratio.Max, expected, 1e-12) || ratio.Bins[0] != 12 {
+		t.Errorf("Expected aspect ratio %g for all 12 triangles, found %+v", expected, ratio)
+	}
+}
+
+func TestStatisticsWithTolerance(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{1, 1, 1})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {1, 0, 0}, {0.5, 0.00001, 0}}})
+	for _, tol := range []float64{0, 0.0001} {
+		stats := s.StatisticsWithTolerance(tol)
+		degenerate := 0
+		for _, te := range s.ValidateWithTolerance(tol) {
+			if te.IsDegenerate {
+				degenerate++
+			}
+		}
+		if stats.DegenerateCount != degenerate || stats.AspectRatio.Count != 13-degenerate {
+			t.Errorf("Expected %d degenerate triangles with tolerance %g like Validate, found %+v", degenerate, tol, stats)
+		}
+	}
+	if stats := s.StatisticsWithTolerance(0.0001); stats.DegenerateCount != 1 {
+		t.Errorf("Expected the sliver to be degenerate with tolerance, found %d", stats.DegenerateCount)
+	}
+}
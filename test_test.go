@@ -6,6 +6,7 @@ package stl
 import (
 	"bytes"
 	"io/ioutil"
+	"math"
 	"testing"
 )
 
@@ -50,6 +51,27 @@ func makeTestSolid() *Solid {
 	}
 }
 
+// makeTorusTestSolid returns a closed torus around the z axis with major
+// radius r1 and minor radius r2, made of 2*n*m triangles.
+func makeTorusTestSolid(r1, r2 float64, n, m int) *Solid {
+	point := func(i, j int) Vec3 {
+		u := TwoPi * float64(i%n) / float64(n)
+		v := TwoPi * float64(j%m) / float64(m)
+		r := r1 + r2*math.Cos(v)
+		return Vec3{r * math.Cos(u), r * math.Sin(u), r2 * math.Sin(v)}
+	}
+	s := &Solid{Name: "Torus"}
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			a, b, c, d := point(i, j), point(i+1, j), point(i+1, j+1), point(i, j+1)
+			s.AppendTriangle(Triangle{Vertices: [3]Vec3{a, b, c}})
+			s.AppendTriangle(Triangle{Vertices: [3]Vec3{a, c, d}})
+		}
+	}
+	s.RecalculateNormals()
+	return s
+}
+
 func TestSolidSameOrderEqual(t *testing.T) {
 	testSolid := makeTestSolid()
 	if !testSolid.sameOrderAlmostEqual(testSolid) {
@@ -72,7 +94,7 @@ func cmpFiles(filename1, filename2 string) (eq bool, err error) {
 	return
 }
 
-// true if s and o are identical up to the 5th decimal
+// true if s and o are identical within 0.000001, with the triangles in the same order
 func (s *Solid) sameOrderAlmostEqual(o *Solid) bool {
 	if !(bytes.Equal(s.BinaryHeader, o.BinaryHeader) &&
 		s.Name == o.Name &&
@@ -0,0 +1,169 @@
+package stl
+
+// This file contains the wall thickness analysis using ray casting.
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// WallThicknessOptions controls Solid.WallThickness.
+type WallThicknessOptions struct {
+	// Threshold is the thickness below which triangles are reported
+	// in WallThicknessReport.ThinRegions.
+	Threshold float64
+
+	// RaysPerTriangle is the number of rays cast from each triangle. The
+	// first one starts at the centroid, the others at pseudo-random points
+	// of the triangle. Values below 1 are treated as 1.
+	RaysPerTriangle int
+
+	// WeldTolerance is used to find the triangles sharing an edge when
+	// grouping thin triangles into regions, see ToIndexed.
+	WeldTolerance float64
+}
+
+// ThinRegion is a group of connected triangles thinner than the threshold,
+// see Solid.WallThickness.
+type ThinRegion struct {
+	// Triangles are the indices in Solid.Triangles, in ascending order.
+	Triangles []int
+
+	// MinThickness is the smallest thickness of the triangles.
+	MinThickness float64
+
+	// Area is the total area of the triangles.
+	Area float64
+}
+
+// WallThicknessReport is the result of Solid.WallThickness.
+type WallThicknessReport struct {
+	// Thickness is the wall thickness by triangle index. It is +Inf for
+	// degenerate triangles, and for triangles whose rays leave the solid
+	// without hitting the opposite surface, which happens for open solids
+	// or wrongly oriented triangles.
+	Thickness []float64
+
+	// Minimum is the smallest thickness, and MinimumTriangle the index of
+	// its triangle. If no thickness was found, they are +Inf and -1.
+	Minimum         float64
+	MinimumTriangle int
+
+	// ThinRegions are the groups of triangles sharing an edge whose
+	// thickness is below the threshold, ordered by their first triangle.
+	ThinRegions []ThinRegion
+}
+
+// WallThickness measures the thickness of the solid's walls. From each
+// triangle, rays are cast inwards, against the normal calculated from the
+// vertices, and the distance to the first triangle hit is recorded. The
+// thickness of a triangle is the smallest distance measured by its rays.
+// The triangles are found using a bounding volume hierarchy, and the rays
+// are cast in parallel. The triangles must be oriented consistently with
+// the normals pointing outside, see FixOrientation.
+func (s *Solid) WallThickness(opts WallThicknessOptions) WallThicknessReport {
+	report := WallThicknessReport{
+		Thickness:       make([]float64, len(s.Triangles)),
+		Minimum:         math.Inf(1),
+		MinimumTriangle: -1,
+	}
+
+	// the same barycentric sample points are used for every triangle, so
+	// the result does not depend on the number of workers
+	var samples [][2]float64
+	if opts.RaysPerTriangle > 1 {
+		samples = make([][2]float64, opts.RaysPerTriangle-1)
+	}
+	r := rand.New(rand.NewSource(1))
+	for k := range samples {
+		samples[k] = [2]float64{r.Float64(), r.Float64()}
+	}
+
+	tree := newBVH(s.Triangles)
+	workers := runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(s.Triangles); i += workers {
+				report.Thickness[i] = s.triangleThickness(tree, i, samples)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for i, thickness := range report.Thickness {
+		if thickness < report.Minimum {
+			report.Minimum = thickness
+			report.MinimumTriangle = i
+		}
+	}
+	report.ThinRegions = s.thinRegions(report.Thickness, opts.Threshold, opts.WeldTolerance)
+	return report
+}
+
+// triangleThickness returns the smallest distance from triangle i to the
+// triangles of tree along rays from its centroid and from samples, given
+// as the arguments of Triangle.samplePoint.
+func (s *Solid) triangleThickness(tree *bvh, i int, samples [][2]float64) float64 {
+	t := &s.Triangles[i]
+	if t.Area() == 0 {
+		return math.Inf(1)
+	}
+	ray := Ray{Origin: t.Centroid(), Direction: t.CalculatedNormal().MultScalar(-1)}
+	thickness, _ := tree.firstHit(ray, i)
+	for _, uv := range samples {
+		ray.Origin = t.samplePoint(uv[0], uv[1])
+		if dist, _ := tree.firstHit(ray, i); dist < thickness {
+			thickness = dist
+		}
+	}
+	return thickness
+}
+
+// thinRegions groups the triangles with thickness below threshold by shared
+// edges, with vertices closer than weldTolerance being equal.
+func (s *Solid) thinRegions(thickness []float64, threshold, weldTolerance float64) []ThinRegion {
+	if threshold <= 0 {
+		return nil
+	}
+	m := s.ToIndexed(weldTolerance)
+	d := newDisjointSet(len(m.Faces))
+	edgeToFace := make(map[[2]uint32]int)
+	for i, f := range m.Faces {
+		if thickness[i] >= threshold {
+			continue
+		}
+		for e := 0; e < 3; e++ {
+			key := undirectedEdge(f[e], f[(e+1)%3])
+			if other, found := edgeToFace[key]; found {
+				d.union(i, other)
+			} else {
+				edgeToFace[key] = i
+			}
+		}
+	}
+
+	var regions []ThinRegion
+	regionByRoot := make(map[int]int)
+	for i := range m.Faces {
+		if thickness[i] >= threshold {
+			continue
+		}
+		root := d.find(i)
+		k, found := regionByRoot[root]
+		if !found {
+			k = len(regions)
+			regionByRoot[root] = k
+			regions = append(regions, ThinRegion{MinThickness: math.Inf(1)})
+		}
+		region := &regions[k]
+		region.Triangles = append(region.Triangles, i)
+		region.MinThickness = math.Min(region.MinThickness, thickness[i])
+		region.Area += s.Triangles[i].Area()
+	}
+	return regions
+}
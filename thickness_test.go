@@ -0,0 +1,64 @@
+package stl
+
+// Tests for the wall thickness analysis.
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWallThickness_Plate(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{10, 8, 2})
+	report := s.WallThickness(WallThicknessOptions{Threshold: 3, RaysPerTriangle: 5})
+	// bottom, top, front, back, left, right with two triangles each
+	expected := []float64{2, 2, 2, 2, 8, 8, 8, 8, 10, 10, 10, 10}
+	for i, thickness := range report.Thickness {
+		if !almostEqual64(thickness, expected[i], 1e-9) {
+			t.Errorf("Expected thickness %g for triangle %d, found %g", expected[i], i, thickness)
+		}
+	}
+	if !almostEqual64(report.Minimum, 2, 1e-9) || report.MinimumTriangle != 0 {
+		t.Errorf("Expected minimum 2 at triangle 0, found %g at %d", report.Minimum, report.MinimumTriangle)
+	}
+
+	// bottom and top are not connected
+	if len(report.ThinRegions) != 2 {
+		t.Fatalf("Expected 2 thin regions, found %+v", report.ThinRegions)
+	}
+	for r, triangles := range [][]int{{0, 1}, {2, 3}} {
+		region := report.ThinRegions[r]
+		if len(region.Triangles) != 2 || region.Triangles[0] != triangles[0] || region.Triangles[1] != triangles[1] {
+			t.Errorf("Expected triangles %v in region %d, found %v", triangles, r, region.Triangles)
+		}
+		if !almostEqual64(region.MinThickness, 2, 1e-9) || !almostEqual64(region.Area, 80, 1e-9) {
+			t.Errorf("Expected thickness 2 and area 80 in region %d, found %+v", r, region)
+		}
+	}
+}
+
+func TestWallThickness_Open(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{1, 1, 1})
+	s.Triangles = s.Triangles[:2]
+	report := s.WallThickness(WallThicknessOptions{Threshold: 1})
+	for i, thickness := range report.Thickness {
+		if !math.IsInf(thickness, 1) {
+			t.Errorf("Expected no thickness for triangle %d, found %g", i, thickness)
+		}
+	}
+	if !math.IsInf(report.Minimum, 1) || report.MinimumTriangle != -1 || len(report.ThinRegions) != 0 {
+		t.Errorf("Expected empty report, found %+v", report)
+	}
+}
+
+func TestBVHFirstHit(t *testing.T) {
+	s := makeBoxTestSolid(Vec3{1, 1, 1})
+	tree := newBVH(s.Triangles)
+	dist, triangle := tree.firstHit(Ray{Origin: Vec3{0.25, 0.5, -1}, Direction: Vec3{0, 0, 1}}, -1)
+	if !almostEqual64(dist, 1, 1e-12) || triangle > 1 {
+		t.Errorf("Expected to hit the bottom at distance 1, found triangle %d at %g", triangle, dist)
+	}
+	dist, triangle = tree.firstHit(Ray{Origin: Vec3{2, 0.5, -1}, Direction: Vec3{0, 0, 1}}, -1)
+	if !math.IsInf(dist, 1) || triangle != -1 {
+		t.Errorf("Expected no hit, found triangle %d at %g", triangle, dist)
+	}
+}
@@ -0,0 +1,194 @@
+package stl
+
+// This file contains the detection and repair of T-junctions.
+
+import (
+	"sort"
+)
+
+// TJunction is a vertex lying on the inside of an edge of another triangle,
+// instead of being connected to it. See Solid.FindTJunctions.
+type TJunction struct {
+	// Triangle is the index in Solid.Triangles of the triangle whose edge
+	// contains the vertex.
+	Triangle int
+
+	// Edge is the index of the edge within the triangle, indexed by its
+	// first vertex like in TriangleErrors.EdgeErrors.
+	Edge int
+
+	// Vertex is the vertex on the edge.
+	Vertex Vec3
+
+	// Position is the relative position of Vertex on the edge, between
+	// 0 at the first and 1 at the second vertex of the edge.
+	Position float64
+}
+
+// FindTJunctions looks for vertices that lie on an edge without counter-edge,
+// within a distance of tol. Only vertices that are themselves part of an edge
+// without counter-edge are considered. The result is ordered by triangle,
+// edge, and position.
+func (s *Solid) FindTJunctions(tol float64) []TJunction {
+	m := s.ToIndexed(0)
+	directed := make(map[[2]uint32]bool, 3*len(m.Faces))
+	for _, f := range m.Faces {
+		for e := 0; e < 3; e++ {
+			directed[[2]uint32{f[e], f[(e+1)%3]}] = true
+		}
+	}
+
+	// collect edges without counter-edge and their vertices
+	type openEdge struct {
+		face, edge int
+	}
+	var openEdges []openEdge
+	isOpenVertex := make([]bool, len(m.Vertices))
+	for i, f := range m.Faces {
+		for e := 0; e < 3; e++ {
+			v, w := f[e], f[(e+1)%3]
+			if v == w || directed[[2]uint32{w, v}] {
+				continue
+			}
+			openEdges = append(openEdges, openEdge{face: i, edge: e})
+			isOpenVertex[v] = true
+			isOpenVertex[w] = true
+		}
+	}
+	if len(openEdges) == 0 {
+		return nil
+	}
+
+	// bounding volume hierarchy on the open edges, given as triangles with
+	// a repeated vertex, queried with the box around every open vertex
+	segments := make([]Triangle, len(openEdges))
+	for k, oe := range openEdges {
+		f := m.Faces[oe.face]
+		b := m.Vertices[f[(oe.edge+1)%3]]
+		segments[k] = Triangle{Vertices: [3]Vec3{m.Vertices[f[oe.edge]], b, b}}
+	}
+	tree := newBVH(segments)
+
+	var junctions []TJunction
+	for u, open := range isOpenVertex {
+		if !open {
+			continue
+		}
+		p := m.Vertices[u]
+		tree.queryBox(aabb{min: p, max: p}.grow(tol), func(k int) bool {
+			oe := openEdges[k]
+			f := m.Faces[oe.face]
+			v, w := f[oe.edge], f[(oe.edge+1)%3]
+			if uint32(u) == v || uint32(u) == w {
+				return true
+			}
+			a, b := m.Vertices[v], m.Vertices[w]
+			dir := b.Diff(a)
+			length := dir.Len()
+			if length <= 2*tol {
+				return true
+			}
+			t := p.Diff(a).Dot(dir) / (length * length)
+			// must be inside the edge, not at one of its vertices
+			if t*length <= tol || (1-t)*length <= tol {
+				return true
+			}
+			if a.Add(dir.MultScalar(t)).Diff(p).Len() <= tol {
+				junctions = append(junctions, TJunction{
+					Triangle: oe.face,
+					Edge:     oe.edge,
+					Vertex:   p,
+					Position: t,
+				})
+			}
+			return true
+		})
+	}
+
+	sort.Slice(junctions, func(i, j int) bool {
+		ji, jj := &junctions[i], &junctions[j]
+		if ji.Triangle != jj.Triangle {
+			return ji.Triangle < jj.Triangle
+		}
+		if ji.Edge != jj.Edge {
+			return ji.Edge < jj.Edge
+		}
+		return ji.Position < jj.Position
+	})
+	return junctions
+}
+
+// FixTJunctions splits the triangles found by FindTJunctions, so the
+// vertices on their edges become proper vertices of them. If the vertices
+// lie on one edge only, the triangle is split by connecting them to the
+// opposite vertex. Otherwise a new vertex at the triangle's centroid is
+// connected to all of them. The new triangles are appended to s.Triangles,
+// replacing the split ones. Returns the number of T-junctions fixed.
+func (s *Solid) FixTJunctions(tol float64) int {
+	junctions := s.FindTJunctions(tol)
+	if len(junctions) == 0 {
+		return 0
+	}
+
+	split := make(map[int]bool)
+	var added []Triangle
+	for start := 0; start < len(junctions); {
+		triangleIndex := junctions[start].Triangle
+		end := start
+		for end < len(junctions) && junctions[end].Triangle == triangleIndex {
+			end++
+		}
+		t := &s.Triangles[triangleIndex]
+		added = append(added, splitTriangleAt(t, junctions[start:end])...)
+		split[triangleIndex] = true
+		start = end
+	}
+
+	s.removeTriangles(func(i int) bool {
+		return split[i]
+	})
+	s.Triangles = append(s.Triangles, added...)
+	return len(junctions)
+}
+
+// splitTriangleAt splits t at the vertices of junctions, which all belong
+// to t and are sorted by edge and position.
+func splitTriangleAt(t *Triangle, junctions []TJunction) []Triangle {
+	// the boundary polygon of t including the junction vertices
+	var polygon []Vec3
+	edgesSplit := 0
+	j := 0
+	for e := 0; e < 3; e++ {
+		polygon = append(polygon, t.Vertices[e])
+		if j < len(junctions) && junctions[j].Edge == e {
+			edgesSplit++
+		}
+		for ; j < len(junctions) && junctions[j].Edge == e; j++ {
+			polygon = append(polygon, junctions[j].Vertex)
+		}
+	}
+
+	newTriangle := func(a, b, c Vec3) Triangle {
+		nt := Triangle{Vertices: [3]Vec3{a, b, c}, Attributes: t.Attributes}
+		nt.recalculateNormal()
+		return nt
+	}
+	var result []Triangle
+	if edgesSplit == 1 {
+		// rotate polygon to start at the vertex opposite to the split edge
+		e := junctions[0].Edge
+		apex := t.Vertices[(e+2)%3]
+		for polygon[0] != apex {
+			polygon = append(polygon[1:], polygon[0])
+		}
+		for i := 1; i < len(polygon)-1; i++ {
+			result = append(result, newTriangle(apex, polygon[i], polygon[i+1]))
+		}
+		return result
+	}
+	centroid := t.Vertices[0].Add(t.Vertices[1]).Add(t.Vertices[2]).MultScalar(1.0 / 3)
+	for i := range polygon {
+		result = append(result, newTriangle(centroid, polygon[i], polygon[(i+1)%len(polygon)]))
+	}
+	return result
+}
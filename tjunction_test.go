@@ -0,0 +1,82 @@
+package stl
+
+// Tests for the detection and repair of T-junctions.
+
+import (
+	"testing"
+)
+
+// makeTJunctionTestSolid returns a flat patch, where vertex {0, 1, 0}
+// lies on the edge {0, 0, 0} -> {0, 2, 0} of triangle 0.
+func makeTJunctionTestSolid() *Solid {
+	a, b, m := Vec3{0, 0, 0}, Vec3{0, 2, 0}, Vec3{0, 1, 0}
+	p, q, r := Vec3{-1, 1, 0}, Vec3{1, 0, 0}, Vec3{1, 2, 0}
+	s := &Solid{Name: "TJunction"}
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{p, a, b}})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{a, q, m}})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{m, r, b}})
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{m, q, r}})
+	s.RecalculateNormals()
+	return s
+}
+
+func TestFindTJunctions(t *testing.T) {
+	junctions := makeTJunctionTestSolid().FindTJunctions(0.000001)
+	if len(junctions) != 1 {
+		t.Fatalf("Expected 1 T-junction, found %v", junctions)
+	}
+	j := junctions[0]
+	if j.Triangle != 0 || j.Edge != 1 || j.Vertex != (Vec3{0, 1, 0}) || !almostEqual64(j.Position, 0.5, 0.000001) {
+		t.Errorf("T-junction not as expected: %+v", j)
+	}
+
+	s := makeTJunctionTestSolid()
+	s.Triangles[1].Vertices[2] = Vec3{0.01, 1, 0}
+	s.Triangles[2].Vertices[0] = Vec3{0.01, 1, 0}
+	s.Triangles[3].Vertices[0] = Vec3{0.01, 1, 0}
+	if junctions := s.FindTJunctions(0.001); len(junctions) != 0 {
+		t.Errorf("Expected no T-junction within tolerance, found %v", junctions)
+	}
+	if junctions := s.FindTJunctions(0.1); len(junctions) != 1 {
+		t.Errorf("Expected 1 T-junction within tolerance, found %v", junctions)
+	}
+}
+
+func TestFixTJunctions(t *testing.T) {
+	s := makeTJunctionTestSolid()
+	if report := s.Topology(0); report.EulerCharacteristic != 0 {
+		t.Errorf("Expected Euler characteristic 0 before repair, found %d", report.EulerCharacteristic)
+	}
+	if fixed := s.FixTJunctions(0.000001); fixed != 1 {
+		t.Errorf("Expected 1 T-junction to be fixed, found %d", fixed)
+	}
+	if len(s.Triangles) != 5 {
+		t.Errorf("Expected 5 triangles, found %d", len(s.Triangles))
+	}
+	report := s.Topology(0)
+	if report.EulerCharacteristic != 1 || report.BoundaryLoops != 1 || !report.IsConsistentlyOriented {
+		t.Errorf("Expected an oriented disk after repair, found %+v", report)
+	}
+	for i, tr := range s.Triangles {
+		if tr.isCollinear(0) {
+			t.Errorf("Triangle %d is degenerate", i)
+		}
+	}
+	if junctions := s.FindTJunctions(0.000001); len(junctions) != 0 {
+		t.Errorf("Expected no T-junctions after repair, found %v", junctions)
+	}
+}
+
+func TestFindTJunctions_LongEdge(t *testing.T) {
+	// many tiny triangles make the long edges of the patch thousands of
+	// times longer than the average edge
+	s := makeTJunctionTestSolid()
+	for i := 0; i < 1000; i++ {
+		x := 10 + 0.01*float64(i)
+		s.AppendTriangle(Triangle{Vertices: [3]Vec3{{x, 0, 0}, {x + 0.001, 0, 0}, {x, 0.001, 0}}})
+	}
+	junctions := s.FindTJunctions(0.000001)
+	if len(junctions) != 1 || junctions[0].Triangle != 0 || junctions[0].Edge != 1 {
+		t.Errorf("Expected 1 T-junction on edge 1 of triangle 0, found %v", junctions)
+	}
+}
@@ -0,0 +1,254 @@
+package stl
+
+// This file contains the global topological analysis of a solid.
+
+// TopologyReport is the result of Solid.Topology.
+type TopologyReport struct {
+	// IsClosed is true if every edge is shared by exactly two triangles,
+	// i.e. the solid is watertight.
+	IsClosed bool
+
+	// IsEdgeManifold is true if no edge is shared by more than two triangles.
+	IsEdgeManifold bool
+
+	// IsVertexManifold is true if the triangles around every vertex form
+	// a single fan, connected by edges.
+	IsVertexManifold bool
+
+	// IsConsistentlyOriented is true if every edge shared by two triangles
+	// is used in opposite directions by them.
+	IsConsistentlyOriented bool
+
+	// NonManifoldEdges is the number of edges shared by more than two triangles.
+	NonManifoldEdges int
+
+	// NonManifoldVertices is the number of vertices with more than one fan
+	// of triangles around them.
+	NonManifoldVertices int
+
+	// BoundaryLoops is the number of holes, i.e. loops of edges belonging
+	// to only one triangle. Loops touching in a vertex are counted as one.
+	BoundaryLoops int
+
+	// EulerCharacteristic is V - E + F for the whole solid.
+	EulerCharacteristic int
+
+	// Shells contains the topology of each connected component, in the
+	// order of Solid.Components.
+	Shells []ShellTopology
+}
+
+// ShellTopology describes the topology of a connected component of a solid.
+type ShellTopology struct {
+	// Number of vertices, edges and triangles
+	Vertices, Edges, Faces int
+
+	// BoundaryLoops is the number of holes in the shell.
+	BoundaryLoops int
+
+	// EulerCharacteristic is V - E + F.
+	EulerCharacteristic int
+
+	// Genus is the number of handles, e.g. 0 for a sphere, and 1 for a torus.
+	// It is -1 if the shell is not manifold, as the genus is not defined then.
+	Genus int
+
+	// IsClosed is true if every edge of the shell is shared by exactly two triangles.
+	IsClosed bool
+
+	// IsManifold is true if there are neither non-manifold edges nor vertices
+	// in the shell. Triangles with equal vertices make a shell neither closed
+	// nor manifold.
+	IsManifold bool
+}
+
+// edgeUse counts the faces using an undirected edge.
+type edgeUse struct {
+	// count by direction, 0 is from the lower to the higher vertex index
+	count [2]int
+
+	// first face using the edge
+	face int
+}
+
+func (e *edgeUse) faces() int {
+	return e.count[0] + e.count[1]
+}
+
+// cornerOf returns the index of the corner of face f at vertex v, being 3*f+k
+// for f[k] == v.
+func (m *IndexedMesh) cornerOf(f int, v uint32) int {
+	face := m.Faces[f]
+	if face[0] == v {
+		return 3 * f
+	} else if face[1] == v {
+		return 3*f + 1
+	}
+	return 3*f + 2
+}
+
+// edgeUses counts the faces using each undirected edge, leaving out edges
+// from a vertex to itself. The corners of faces sharing an edge are merged
+// in the returned disjoint set, so each set contains the corners of a fan
+// of faces around a vertex.
+func (m *IndexedMesh) edgeUses() (map[[2]uint32]*edgeUse, disjointSet) {
+	uses := make(map[[2]uint32]*edgeUse, 3*len(m.Faces)/2)
+	corners := newDisjointSet(3 * len(m.Faces))
+	for i, f := range m.Faces {
+		for e := 0; e < 3; e++ {
+			v, w := f[e], f[(e+1)%3]
+			if v == w {
+				continue
+			}
+			key := undirectedEdge(v, w)
+			direction := 0
+			if v > w {
+				direction = 1
+			}
+			use, found := uses[key]
+			if !found {
+				use = &edgeUse{face: i}
+				uses[key] = use
+			} else {
+				corners.union(3*i+e, m.cornerOf(use.face, v))
+				corners.union(3*i+(e+1)%3, m.cornerOf(use.face, w))
+			}
+			use.count[direction]++
+		}
+	}
+	return uses, corners
+}
+
+// nonManifoldVertices returns the vertices with more than one fan of faces
+// around them, using the corner sets from edgeUses. For each of them, the
+// index of a face containing the vertex is returned, too.
+func (m *IndexedMesh) nonManifoldVertices(corners disjointSet) map[uint32]int {
+	firstRoot := make([]int, len(m.Vertices))
+	for v := range firstRoot {
+		firstRoot[v] = -1
+	}
+	nonManifold := make(map[uint32]int)
+	for c := range corners {
+		v := m.Faces[c/3][c%3]
+		root := corners.find(c)
+		if r := firstRoot[v]; r < 0 {
+			firstRoot[v] = root
+		} else if r != root {
+			nonManifold[v] = c / 3
+		}
+	}
+	return nonManifold
+}
+
+// Topology analyzes the solid as a whole. Vertices closer to each other than
+// weldTolerance are considered equal, see ToIndexed.
+func (s *Solid) Topology(weldTolerance float64) TopologyReport {
+	return s.ToIndexed(weldTolerance).Topology()
+}
+
+// Topology analyzes the mesh as a whole, see Solid.Topology.
+func (m *IndexedMesh) Topology() TopologyReport {
+	report := TopologyReport{
+		IsClosed:               true,
+		IsEdgeManifold:         true,
+		IsVertexManifold:       true,
+		IsConsistentlyOriented: true,
+	}
+
+	labels, shellCount := m.componentLabels()
+	report.Shells = make([]ShellTopology, shellCount)
+	for i := range report.Shells {
+		report.Shells[i].IsClosed = true
+		report.Shells[i].IsManifold = true
+	}
+	for i, label := range labels {
+		report.Shells[label].Faces++
+		// a face with equal vertices uses its edge in both directions,
+		// which would look like a closed surface
+		if m.hasEqualVertices(i) {
+			report.Shells[label].IsClosed = false
+			report.Shells[label].IsManifold = false
+		}
+	}
+
+	// vertices, counted once per shell they are used in
+	vertexSeen := make(map[[2]uint32]bool, len(m.Vertices))
+	vertexUsed := make([]bool, len(m.Vertices))
+	usedVertices := 0
+	for i, f := range m.Faces {
+		for _, v := range f {
+			key := [2]uint32{uint32(labels[i]), v}
+			if !vertexSeen[key] {
+				vertexSeen[key] = true
+				report.Shells[labels[i]].Vertices++
+			}
+			if !vertexUsed[v] {
+				vertexUsed[v] = true
+				usedVertices++
+			}
+		}
+	}
+
+	uses, corners := m.edgeUses()
+	boundaryVertices := newDisjointSet(len(m.Vertices))
+	var boundaryEdges [][2]uint32
+	for edge, use := range uses {
+		shell := &report.Shells[labels[use.face]]
+		shell.Edges++
+		switch faces := use.faces(); {
+		case faces == 1:
+			shell.IsClosed = false
+			boundaryVertices.union(int(edge[0]), int(edge[1]))
+			boundaryEdges = append(boundaryEdges, edge)
+		case faces == 2:
+			if use.count[0] != 1 {
+				report.IsConsistentlyOriented = false
+			}
+		default:
+			shell.IsClosed = false
+			shell.IsManifold = false
+			report.NonManifoldEdges++
+		}
+	}
+
+	boundaryLoopSeen := make(map[int]bool)
+	for _, edge := range boundaryEdges {
+		root := boundaryVertices.find(int(edge[0]))
+		if !boundaryLoopSeen[root] {
+			boundaryLoopSeen[root] = true
+			report.Shells[labels[uses[edge].face]].BoundaryLoops++
+		}
+	}
+
+	report.NonManifoldVertices = len(m.nonManifoldVertices(corners))
+	// A vertex only touching different shells is fine for each of them
+	firstRoot := make(map[[2]uint32]int, len(vertexSeen))
+	for c := range corners {
+		shell := labels[c/3]
+		key := [2]uint32{uint32(shell), m.Faces[c/3][c%3]}
+		root := corners.find(c)
+		if r, found := firstRoot[key]; !found {
+			firstRoot[key] = root
+		} else if r != root {
+			report.Shells[shell].IsManifold = false
+		}
+	}
+
+	for i := range report.Shells {
+		shell := &report.Shells[i]
+		shell.EulerCharacteristic = shell.Vertices - shell.Edges + shell.Faces
+		if shell.IsManifold {
+			// Euler-Poincaré formula for orientable surfaces: V - E + F = 2 - 2g - b
+			shell.Genus = (2 - shell.BoundaryLoops - shell.EulerCharacteristic) / 2
+		} else {
+			shell.Genus = -1
+		}
+		report.IsClosed = report.IsClosed && shell.IsClosed
+		report.BoundaryLoops += shell.BoundaryLoops
+	}
+	report.EulerCharacteristic = usedVertices - len(uses) + len(m.Faces)
+	report.IsEdgeManifold = report.NonManifoldEdges == 0
+	report.IsVertexManifold = report.NonManifoldVertices == 0
+
+	return report
+}
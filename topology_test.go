@@ -0,0 +1,94 @@
+package stl
+
+// Tests for the topological analysis of solids.
+
+import (
+	"testing"
+)
+
+func TestTopology_Closed(t *testing.T) {
+	report := makeTestSolid().Topology(0)
+	if !report.IsClosed || !report.IsEdgeManifold || !report.IsVertexManifold || !report.IsConsistentlyOriented {
+		t.Errorf("Expected closed, manifold, and oriented solid, found %+v", report)
+	}
+	if report.EulerCharacteristic != 2 || report.BoundaryLoops != 0 {
+		t.Errorf("Expected Euler characteristic 2 and no boundary loops, found %+v", report)
+	}
+	if len(report.Shells) != 1 || report.Shells[0].Genus != 0 {
+		t.Errorf("Expected one shell of genus 0, found %+v", report.Shells)
+	}
+}
+
+func TestTopology_Torus(t *testing.T) {
+	report := makeTorusTestSolid(2, 0.5, 12, 8).Topology(0.000001)
+	if !report.IsClosed || !report.IsEdgeManifold || !report.IsVertexManifold {
+		t.Errorf("Expected closed manifold solid, found %+v", report)
+	}
+	if report.EulerCharacteristic != 0 {
+		t.Errorf("Expected Euler characteristic 0, found %d", report.EulerCharacteristic)
+	}
+	if len(report.Shells) != 1 || report.Shells[0].Genus != 1 {
+		t.Errorf("Expected one shell of genus 1, found %+v", report.Shells)
+	}
+}
+
+func TestTopology_Open(t *testing.T) {
+	s := makeTestSolid()
+	s.Triangles = s.Triangles[1:]
+	report := s.Topology(0)
+	if report.IsClosed || report.BoundaryLoops != 1 {
+		t.Errorf("Expected open solid with one boundary loop, found %+v", report)
+	}
+	if len(report.Shells) != 1 || report.Shells[0].Genus != 0 || report.Shells[0].EulerCharacteristic != 1 {
+		t.Errorf("Expected one shell of genus 0 with Euler characteristic 1, found %+v", report.Shells)
+	}
+}
+
+func TestTopology_NonManifold(t *testing.T) {
+	// Two tetrahedra touching in a single vertex
+	s := makeTestSolid()
+	moved := makeTestSolid()
+	moved.Translate(Vec3{1, 0, 0})
+	s.Triangles = append(s.Triangles, moved.Triangles...)
+	report := s.Topology(0)
+	if report.IsVertexManifold || report.NonManifoldVertices != 1 {
+		t.Errorf("Expected one non-manifold vertex, found %+v", report)
+	}
+	if len(report.Shells) != 2 || !report.Shells[0].IsManifold || !report.Shells[1].IsManifold {
+		t.Errorf("Expected two manifold shells, found %+v", report.Shells)
+	}
+
+	// Flipping a triangle breaks orientation
+	s = makeTestSolid()
+	s.Triangles[0].Vertices[0], s.Triangles[0].Vertices[1] = s.Triangles[0].Vertices[1], s.Triangles[0].Vertices[0]
+	if report := s.Topology(0); report.IsConsistentlyOriented || !report.IsClosed {
+		t.Errorf("Expected closed but inconsistently oriented solid, found %+v", report)
+	}
+
+	// Three triangles sharing an edge
+	s = makeTestSolid()
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {0, 1, 0}, {-1, 0, 0}}})
+	if report := s.Topology(0); report.IsEdgeManifold || report.NonManifoldEdges != 1 || report.Shells[0].Genus != -1 {
+		t.Errorf("Expected one non-manifold edge, found %+v", report)
+	}
+}
+
+func TestTopology_Degenerate(t *testing.T) {
+	// a sliver uses its only edge in both directions
+	var s Solid
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {0, 0, 0}, {1, 0, 0}}})
+	report := s.Topology(0)
+	if report.IsClosed || len(report.Shells) != 1 || report.Shells[0].IsClosed ||
+		report.Shells[0].IsManifold || report.Shells[0].Genus != -1 {
+		t.Errorf("Expected an open non-manifold shell, found %+v", report)
+	}
+
+	// a sliver touching a closed solid in a vertex forms a shell of its own
+	s = *makeTestSolid()
+	s.AppendTriangle(Triangle{Vertices: [3]Vec3{{0, 0, 0}, {0, 0, 0}, {-1, 0, 0}}})
+	report = s.Topology(0)
+	if report.IsClosed || len(report.Shells) != 2 || !report.Shells[0].IsClosed ||
+		report.Shells[1].IsClosed || report.Shells[1].IsManifold {
+		t.Errorf("Expected a closed shell and an open non-manifold one, found %+v", report)
+	}
+}
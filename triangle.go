@@ -2,6 +2,10 @@ package stl
 
 // This file defines the Triangle data type, the building block for Solid
 
+import (
+	"math"
+)
+
 // Triangle represents single triangles used in Solid.Triangles. The vertices
 // have to be ordered counter-clockwise when looking at their outside surface.
 // The vector Normal is orthogonal to the triangle, pointing outside, and
@@ -22,8 +26,10 @@ type Triangle struct {
 	Attributes uint16
 }
 
-// Calculate the normal vector using the right hand rule
-func (t *Triangle) calculateNormal() Vec3 {
+// CalculatedNormal returns the unit normal vector calculated from the
+// vertices using the right hand rule, independently of t.Normal. It is
+// Vec3Zero for triangles with equal vertices.
+func (t *Triangle) CalculatedNormal() Vec3 {
 	// The normal is calculated by normalizing the result of
 	// (V0-V2) x (V1-V2)
 	return t.Vertices[0].Diff(t.Vertices[2]).
@@ -33,7 +39,7 @@ func (t *Triangle) calculateNormal() Vec3 {
 
 // Recalculate the redundant normal vector using the right hand rule
 func (t *Triangle) recalculateNormal() {
-	t.Normal = t.calculateNormal()
+	t.Normal = t.CalculatedNormal()
 }
 
 // Applies a 4x4 transformation matrix to every vertex
@@ -59,9 +65,87 @@ func (t *Triangle) hasEqualVertices() bool {
 		t.Vertices[1] == t.Vertices[2]
 }
 
+// Area returns the area of the triangle.
+func (t *Triangle) Area() float64 {
+	return 0.5 * t.Vertices[1].Diff(t.Vertices[0]).
+		Cross(t.Vertices[2].Diff(t.Vertices[0])).
+		Len()
+}
+
+// Centroid returns the center of gravity of the triangle, the average of
+// its vertices.
+func (t *Triangle) Centroid() Vec3 {
+	return t.Vertices[0].Add(t.Vertices[1]).Add(t.Vertices[2]).MultScalar(1.0 / 3)
+}
+
+// EdgeLengths returns the lengths of the edges from vertex i to vertex i+1,
+// and from vertex 2 to vertex 0.
+func (t *Triangle) EdgeLengths() [3]float64 {
+	return [3]float64{
+		t.Vertices[1].Diff(t.Vertices[0]).Len(),
+		t.Vertices[2].Diff(t.Vertices[1]).Len(),
+		t.Vertices[0].Diff(t.Vertices[2]).Len(),
+	}
+}
+
+// AspectRatio returns the ratio of the circumradius to twice the inradius,
+// which is 1 for equilateral triangles and grows as triangles get thinner.
+// For degenerate triangles it is +Inf.
+func (t *Triangle) AspectRatio() float64 {
+	// with semiperimeter s, the circumradius is abc/(4 area) and the
+	// inradius area/s
+	area := t.Area()
+	if area == 0 {
+		return math.Inf(1)
+	}
+	e := t.EdgeLengths()
+	s := (e[0] + e[1] + e[2]) / 2
+	return math.Max(1, e[0]*e[1]*e[2]*s/(8*area*area))
+}
+
+// MinAngle returns the smallest interior angle of the triangle in radians.
+// It is 0 for degenerate triangles.
+func (t *Triangle) MinAngle() float64 {
+	minAngle := math.Pi
+	for v := 0; v < 3; v++ {
+		a := t.Vertices[(v+1)%3].Diff(t.Vertices[v])
+		b := t.Vertices[(v+2)%3].Diff(t.Vertices[v])
+		if a == Vec3Zero || b == Vec3Zero {
+			return 0
+		}
+		minAngle = math.Min(minAngle, a.Angle(b))
+	}
+	return minAngle
+}
+
+// Returns the length of the longest edge.
+func (t *Triangle) longestEdge() float64 {
+	return max(t.Vertices[1].Diff(t.Vertices[0]).Len(),
+		max(t.Vertices[2].Diff(t.Vertices[1]).Len(), t.Vertices[0].Diff(t.Vertices[2]).Len()))
+}
+
+// Returns true if the triangle's height over its longest edge is not greater
+// than tol, meaning the vertices are collinear allowing for numerical error tol.
+func (t *Triangle) isCollinear(tol float64) bool {
+	return 2*t.Area() <= tol*t.longestEdge()
+}
+
 // Checks if normal matches vertices using right hand rule, with
 // numerical tolerance for Angle between them given by tol in radians.
 func (t *Triangle) checkNormal(tol float64) bool {
-	calculatedNormal := t.calculateNormal()
+	calculatedNormal := t.CalculatedNormal()
 	return t.Normal.Angle(calculatedNormal) < tol
 }
+
+// Returns the signed volume of the tetrahedron spanned by the origin
+// and the triangle. Summed up over a closed solid, this is the enclosed volume.
+func (t *Triangle) signedVolume() float64 {
+	return t.Vertices[0].Dot(t.Vertices[1].Cross(t.Vertices[2])) / 6
+}
+
+// Reverses the orientation of the triangle by swapping two vertices,
+// and inverts the normal vector accordingly.
+func (t *Triangle) flip() {
+	t.Vertices[1], t.Vertices[2] = t.Vertices[2], t.Vertices[1]
+	t.Normal = t.Normal.MultScalar(-1)
+}
@@ -0,0 +1,47 @@
+package stl
+
+// Tests for the geometry of the Triangle data type.
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTriangleGeometry(t *testing.T) {
+	tri := Triangle{Vertices: [3]Vec3{{0, 0, 0}, {4, 0, 0}, {0, 3, 0}}}
+	if area := tri.Area(); !almostEqual64(area, 6, 1e-12) {
+		t.Errorf("Expected area 6, found %g", area)
+	}
+	if c := tri.Centroid(); !c.AlmostEqual(Vec3{4.0 / 3, 1, 0}, 1e-12) {
+		t.Errorf("Expected centroid [4/3 1 0], found %v", c)
+	}
+	if n := tri.CalculatedNormal(); !n.AlmostEqual(Vec3{0, 0, 1}, 1e-12) {
+		t.Errorf("Expected normal [0 0 1], found %v", n)
+	}
+	if e := tri.EdgeLengths(); !almostEqual64(e[0], 4, 1e-12) || !almostEqual64(e[1], 5, 1e-12) || !almostEqual64(e[2], 3, 1e-12) {
+		t.Errorf("Expected edge lengths [4 5 3], found %v", e)
+	}
+	// circumradius 2.5, inradius 1
+	if ar := tri.AspectRatio(); !almostEqual64(ar, 1.25, 1e-12) {
+		t.Errorf("Expected aspect ratio 1.25, found %g", ar)
+	}
+	if a := tri.MinAngle(); !almostEqual64(a, math.Atan2(3, 4), 1e-12) {
+		t.Errorf("Expected minimum angle %g, found %g", math.Atan2(3, 4), a)
+	}
+
+	equilateral := Triangle{Vertices: [3]Vec3{{0, 0, 0}, {2, 0, 0}, {1, math.Sqrt(3), 0}}}
+	if ar := equilateral.AspectRatio(); !almostEqual64(ar, 1, 1e-12) {
+		t.Errorf("Expected aspect ratio 1 for equilateral triangle, found %g", ar)
+	}
+	if a := equilateral.MinAngle(); !almostEqual64(a, math.Pi/3, 1e-12) {
+		t.Errorf("Expected minimum angle pi/3 for equilateral triangle, found %g", a)
+	}
+
+	degenerate := Triangle{Vertices: [3]Vec3{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}}}
+	if ar := degenerate.AspectRatio(); !math.IsInf(ar, 1) {
+		t.Errorf("Expected infinite aspect ratio for degenerate triangle, found %g", ar)
+	}
+	if a := degenerate.MinAngle(); a != 0 {
+		t.Errorf("Expected minimum angle 0 for degenerate triangle, found %g", a)
+	}
+}
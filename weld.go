@@ -0,0 +1,147 @@
+package stl
+
+// This file contains functions to merge vertices that are closer to each
+// other than a given tolerance, backed by a spatial hash.
+
+import (
+	"math"
+)
+
+// vertexWelder assigns indices to vertices. Vertices within a distance
+// of tol from an already known vertex get the index of that vertex, which
+// becomes their representative. If tol is <= 0, only exactly equal vertices
+// are merged.
+type vertexWelder struct {
+	tol      float64
+	vertices []Vec3
+	exact    map[Vec3]uint32
+	cells    map[[3]int64][]uint32
+}
+
+func newVertexWelder(tol float64, capacity int) *vertexWelder {
+	w := vertexWelder{
+		tol:      tol,
+		vertices: make([]Vec3, 0, capacity),
+	}
+	if tol > 0 {
+		w.cells = make(map[[3]int64][]uint32, capacity)
+	} else {
+		w.exact = make(map[Vec3]uint32, capacity)
+	}
+	return &w
+}
+
+// weldCellFactor is the size of the spatial hash cells relative to the
+// tolerance. Larger cells mean that fewer neighboring cells have to be
+// searched, as only those closer than tol to a vertex are relevant.
+const weldCellFactor = 4
+
+// cell returns the spatial hash cell containing v.
+func (w *vertexWelder) cell(v Vec3) [3]int64 {
+	size := weldCellFactor * w.tol
+	return [3]int64{
+		cellCoordinate(v[0], size),
+		cellCoordinate(v[1], size),
+		cellCoordinate(v[2], size),
+	}
+}
+
+// maxCellCoordinate is the largest absolute value returned by cellCoordinate.
+// It is far from the limits of int64, so loops over a range of cells end.
+const maxCellCoordinate = 1 << 52
+
+// cellCoordinate returns the index of the cell of size containing x along
+// one axis. Indices too large for int64 are clamped, so vertices far away
+// relative to the cell size share cells. This is slow, but still correct.
+func cellCoordinate(x, size float64) int64 {
+	c := math.Floor(x / size)
+	if c > maxCellCoordinate {
+		return maxCellCoordinate
+	}
+	if !(c >= -maxCellCoordinate) { // also for NaN
+		return -maxCellCoordinate
+	}
+	return int64(c)
+}
+
+// find returns the index of the representative for v, if there is one.
+func (w *vertexWelder) find(v Vec3) (index uint32, found bool) {
+	if w.exact != nil {
+		index, found = w.exact[v]
+		return
+	}
+	// the range of cells within tol of v
+	lo, hi := w.cell(v.Diff(Vec3{w.tol, w.tol, w.tol})), w.cell(v.Add(Vec3{w.tol, w.tol, w.tol}))
+	bestDist := math.Inf(1)
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for z := lo[2]; z <= hi[2]; z++ {
+				for _, i := range w.cells[[3]int64{x, y, z}] {
+					dist := w.vertices[i].Diff(v).Len()
+					if dist <= w.tol && dist < bestDist {
+						bestDist = dist
+						index = i
+						found = true
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// index returns the index of the representative of v, adding v as a new
+// representative if there is none yet.
+func (w *vertexWelder) index(v Vec3) uint32 {
+	if i, found := w.find(v); found {
+		return i
+	}
+	i := uint32(len(w.vertices))
+	w.vertices = append(w.vertices, v)
+	if w.exact != nil {
+		w.exact[v] = i
+	} else {
+		c := w.cell(v)
+		w.cells[c] = append(w.cells[c], i)
+	}
+	return i
+}
+
+// WeldVertices moves vertices closer to each other than tol onto a shared
+// position, so they become exactly equal. The position of the first vertex
+// found in s.Triangles is used. Returns the number of vertices moved.
+// Normals are not recalculated, use RecalculateNormals if necessary.
+func (s *Solid) WeldVertices(tol float64) int {
+	moved := 0
+	w := newVertexWelder(tol, len(s.Triangles)/2)
+	for i := range s.Triangles {
+		t := &s.Triangles[i]
+		for v := 0; v < 3; v++ {
+			rep := w.vertices[w.index(t.Vertices[v])]
+			if rep != t.Vertices[v] {
+				t.Vertices[v] = rep
+				moved++
+			}
+		}
+	}
+	return moved
+}
+
+// WeldVertices merges vertices closer to each other than tol into one,
+// updating the faces using them. The first vertex found in m.Vertices is
+// kept, and the vertices keep their order. Returns the number of vertices
+// removed. Normals are not recalculated, use RecalculateNormals if necessary.
+func (m *IndexedMesh) WeldVertices(tol float64) int {
+	w := newVertexWelder(tol, len(m.Vertices))
+	index := make([]uint32, len(m.Vertices))
+	for i, v := range m.Vertices {
+		index[i] = w.index(v)
+	}
+	for i := range m.Faces {
+		f := &m.Faces[i]
+		f[0], f[1], f[2] = index[f[0]], index[f[1]], index[f[2]]
+	}
+	removed := len(m.Vertices) - len(w.vertices)
+	m.Vertices = w.vertices
+	return removed
+}
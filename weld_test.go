@@ -0,0 +1,83 @@
+package stl
+
+// Tests for welding vertices.
+
+import (
+	"math"
+	"testing"
+)
+
+func makeRoundedTestSolid() *Solid {
+	s := makeTestSolid()
+	s.Triangles[2].Vertices[0] = Vec3{0, 0, 1.0000001}
+	s.Triangles[3].Vertices[2] = Vec3{0.0000001, 1, 0}
+	return s
+}
+
+func TestWeldVertices(t *testing.T) {
+	s := makeRoundedTestSolid()
+	if errors := s.Validate(); len(errors) == 0 {
+		t.Error("Expected errors before welding")
+	}
+	if moved := s.WeldVertices(0.00001); moved != 2 {
+		t.Errorf("Expected 2 vertices to be moved, found %d", moved)
+	}
+	if errors := s.Validate(); len(errors) != 0 {
+		t.Errorf("Expected no errors after welding, found %v", errors)
+	}
+	if !s.sameOrderAlmostEqual(makeTestSolid()) {
+		t.Error("Expected original positions after welding")
+	}
+}
+
+func TestWeldVertices_NoTolerance(t *testing.T) {
+	s := makeRoundedTestSolid()
+	if moved := s.WeldVertices(0); moved != 0 {
+		t.Errorf("Expected no vertices to be moved, found %d", moved)
+	}
+}
+
+func TestIndexedMesh_WeldVertices(t *testing.T) {
+	m := makeRoundedTestSolid().ToIndexed(0)
+	if removed := m.WeldVertices(0.00001); removed != 2 || len(m.Vertices) != 4 {
+		t.Errorf("Expected 2 of 6 vertices to be removed, found %d removed and %d left", removed, len(m.Vertices))
+	}
+	if errors := m.Validate(); len(errors) != 0 {
+		t.Errorf("Expected no errors after welding, found %v", errors)
+	}
+	if !m.ToSolid().sameOrderAlmostEqual(makeTestSolid()) {
+		t.Error("Expected original positions after welding")
+	}
+}
+
+func TestWeldVertices_TinyTolerance(t *testing.T) {
+	// the cell coordinates exceed the range of int64
+	s := makeTestSolid()
+	s.Translate(Vec3{1e6, -1e6, 0})
+	if errors := s.ValidateWithTolerance(1e-20); len(errors) != 0 {
+		t.Errorf("Expected no errors, found %v", errors)
+	}
+	for _, test := range []struct {
+		x, size  float64
+		expected int64
+	}{
+		{x: 1e300, size: 1e-300, expected: maxCellCoordinate},
+		{x: -1e300, size: 1e-300, expected: -maxCellCoordinate},
+		{x: math.NaN(), size: 1, expected: -maxCellCoordinate},
+		{x: -2.5, size: 1, expected: -3},
+	} {
+		if c := cellCoordinate(test.x, test.size); c != test.expected {
+			t.Errorf("Expected cell %d for %g with size %g, found %d", test.expected, test.x, test.size, c)
+		}
+	}
+}
+
+func TestValidateWithTolerance(t *testing.T) {
+	s := makeRoundedTestSolid()
+	if errors := s.ValidateWithTolerance(0.00001); len(errors) != 0 {
+		t.Errorf("Expected no errors with tolerance, found %v", errors)
+	}
+	if errors := makeBrokenTestSolid().ValidateWithTolerance(0.00001); len(errors) == 0 {
+		t.Error("Expected errors in broken solid")
+	}
+}
@@ -8,9 +8,20 @@ import (
 	"math"
 )
 
+// MaxBinaryTriangleCount is the maximum number of triangles that fit into
+// a binary STL file, as the triangle count is stored as uint32.
+const MaxBinaryTriangleCount = math.MaxUint32
+
+// maxBinaryTriangles is MaxBinaryTriangleCount limited to the range of int,
+// which only makes a difference on 32 bit platforms.
+const maxBinaryTriangles = int(MaxBinaryTriangleCount & uint64(^uint(0)>>1))
+
 // Write solid in binary STL into an io.Writer.
-// Does not check whether len(solid.Triangles) fits into uint32.
+// Returns ErrTooManyTriangles if len(solid.Triangles) does not fit into uint32.
 func writeSolidBinary(w io.Writer, solid *Solid) error {
+	if uint64(len(solid.Triangles)) > MaxBinaryTriangleCount {
+		return ErrTooManyTriangles
+	}
 	headerBuf := make([]byte, binaryHeaderSize)
 	if solid.BinaryHeader == nil {
 		// use name if no binary header set